@@ -0,0 +1,315 @@
+package api
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"optii/models"
+)
+
+// Entity names a kind of reference data kept by the cache.
+type Entity string
+
+const (
+	Departments   Entity = "departments"
+	JobItems      Entity = "job_items"
+	Locations     Entity = "locations"
+	LocationTypes Entity = "location_types"
+)
+
+// CacheConfig controls how one kind of reference data is cached.
+type CacheConfig struct {
+	// TTL is how long a response is served without asking Optii again. Zero disables caching.
+	TTL time.Duration
+	// StaleTTL is how long after the TTL an expired response is still served while it is refreshed in the background.
+	StaleTTL time.Duration
+	// MaxEntries bounds the number of cached responses, dropping the least recently used first. Zero means unbounded.
+	MaxEntries int
+}
+
+// CachePolicy holds the cache configuration of every kind of reference data.
+type CachePolicy struct {
+	Departments   CacheConfig
+	JobItems      CacheConfig
+	Locations     CacheConfig
+	LocationTypes CacheConfig
+}
+
+// DefaultCachePolicy caches departments, job items and location types for longer than locations,
+// which are renamed and moved more often.
+var DefaultCachePolicy = CachePolicy{
+	Departments:   CacheConfig{TTL: time.Minute * 10, StaleTTL: time.Minute * 5, MaxEntries: 1000},
+	JobItems:      CacheConfig{TTL: time.Minute * 10, StaleTTL: time.Minute * 5, MaxEntries: 1000},
+	Locations:     CacheConfig{TTL: time.Minute * 5, StaleTTL: time.Minute * 5, MaxEntries: 5000},
+	LocationTypes: CacheConfig{TTL: time.Hour, StaleTTL: time.Minute * 5, MaxEntries: 100},
+}
+
+// CachedOptiiApi is an OptiiApi that keeps the reference data it reads. Jobs are never cached.
+type CachedOptiiApi interface {
+	OptiiApi
+	// Invalidate drops the cached responses of the given kinds of reference data, or of all of them.
+	Invalidate(entities ...Entity)
+}
+
+type cachedOptiiApi struct {
+	OptiiApi
+
+	department    *cache[*models.Department]
+	departments   *cache[*models.Departments]
+	jobItem       *cache[*models.JobItem]
+	jobItems      *cache[*models.JobItems]
+	location      *cache[*models.Location]
+	locations     *cache[*models.Locations]
+	locationType  *cache[*models.LocationType]
+	locationTypes *cache[*models.LocationTypes]
+}
+
+// NewCachedOptiiApi wraps the client with a cache for departments, job items, locations and location types.
+// Failed calls are not cached.
+func NewCachedOptiiApi(next OptiiApi, policy CachePolicy) CachedOptiiApi {
+	return &cachedOptiiApi{
+		OptiiApi:      next,
+		department:    newCache[*models.Department](policy.Departments),
+		departments:   newCache[*models.Departments](policy.Departments),
+		jobItem:       newCache[*models.JobItem](policy.JobItems),
+		jobItems:      newCache[*models.JobItems](policy.JobItems),
+		location:      newCache[*models.Location](policy.Locations),
+		locations:     newCache[*models.Locations](policy.Locations),
+		locationType:  newCache[*models.LocationType](policy.LocationTypes),
+		locationTypes: newCache[*models.LocationTypes](policy.LocationTypes),
+	}
+}
+
+func (c *cachedOptiiApi) Invalidate(entities ...Entity) {
+	if len(entities) == 0 {
+		entities = []Entity{Departments, JobItems, Locations, LocationTypes}
+	}
+	for _, entity := range entities {
+		switch entity {
+		case Departments:
+			c.department.clear()
+			c.departments.clear()
+		case JobItems:
+			c.jobItem.clear()
+			c.jobItems.clear()
+		case Locations:
+			c.location.clear()
+			c.locations.clear()
+		case LocationTypes:
+			c.locationType.clear()
+			c.locationTypes.clear()
+		}
+	}
+}
+
+func (c *cachedOptiiApi) GetDepartment(ctx context.Context, id int) (*models.Department, error) {
+	return c.department.get(ctx, fmt.Sprint(id), func(ctx context.Context) (*models.Department, error) {
+		return c.OptiiApi.GetDepartment(ctx, id)
+	})
+}
+
+func (c *cachedOptiiApi) GetDepartments(ctx context.Context, displayName string, first, next int) (*models.Departments, error) {
+	key := fmt.Sprintf("%s|%d|%d", displayName, first, next)
+	return c.departments.get(ctx, key, func(ctx context.Context) (*models.Departments, error) {
+		return c.OptiiApi.GetDepartments(ctx, displayName, first, next)
+	})
+}
+
+func (c *cachedOptiiApi) GetJobItem(ctx context.Context, jobItemId int) (*models.JobItem, error) {
+	return c.jobItem.get(ctx, fmt.Sprint(jobItemId), func(ctx context.Context) (*models.JobItem, error) {
+		return c.OptiiApi.GetJobItem(ctx, jobItemId)
+	})
+}
+
+func (c *cachedOptiiApi) GetJobItems(ctx context.Context, first int, next int, displayName string) (*models.JobItems, error) {
+	key := fmt.Sprintf("%s|%d|%d", displayName, first, next)
+	return c.jobItems.get(ctx, key, func(ctx context.Context) (*models.JobItems, error) {
+		return c.OptiiApi.GetJobItems(ctx, first, next, displayName)
+	})
+}
+
+func (c *cachedOptiiApi) GetLocation(ctx context.Context, locationId int) (*models.Location, error) {
+	return c.location.get(ctx, fmt.Sprint(locationId), func(ctx context.Context) (*models.Location, error) {
+		return c.OptiiApi.GetLocation(ctx, locationId)
+	})
+}
+
+func (c *cachedOptiiApi) GetLocations(ctx context.Context, params map[string]string) (*models.Locations, error) {
+	return c.locations.get(ctx, paramsKey(params), func(ctx context.Context) (*models.Locations, error) {
+		return c.OptiiApi.GetLocations(ctx, params)
+	})
+}
+
+func (c *cachedOptiiApi) GetLocationTypes(ctx context.Context, first, next int) (*models.LocationTypes, error) {
+	key := fmt.Sprintf("%d|%d", first, next)
+	return c.locationTypes.get(ctx, key, func(ctx context.Context) (*models.LocationTypes, error) {
+		return c.OptiiApi.GetLocationTypes(ctx, first, next)
+	})
+}
+
+func (c *cachedOptiiApi) GetLocationType(ctx context.Context, locationTypeId int) (*models.LocationType, error) {
+	return c.locationType.get(ctx, fmt.Sprint(locationTypeId), func(ctx context.Context) (*models.LocationType, error) {
+		return c.OptiiApi.GetLocationType(ctx, locationTypeId)
+	})
+}
+
+// paramsKey turns query parameters into a cache key that does not depend on map order.
+func paramsKey(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s=%s&", key, params[key])
+	}
+	return b.String()
+}
+
+// cache is a size bounded, least recently used cache of the responses of one kind of call.
+// Concurrent misses for the same key share a single call to Optii.
+type cache[V any] struct {
+	config CacheConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	entries  map[string]*list.Element
+	order    *list.List
+	inflight map[string]*cacheLoad[V]
+	// generation changes on every clear, so responses loaded before it are not stored.
+	generation int
+}
+
+type cacheEntry[V any] struct {
+	key        string
+	value      V
+	fetchedAt  time.Time
+	refreshing bool
+}
+
+type cacheLoad[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+func newCache[V any](config CacheConfig) *cache[V] {
+	return &cache[V]{
+		config:   config,
+		now:      time.Now,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		inflight: make(map[string]*cacheLoad[V]),
+	}
+}
+
+// get returns the cached value of key, calling load when there is none or it expired.
+// A value past its TTL but within its stale TTL is returned at once and refreshed in the background.
+func (c *cache[V]) get(ctx context.Context, key string, load func(context.Context) (V, error)) (V, error) {
+	if c.config.TTL <= 0 {
+		return load(ctx)
+	}
+
+	c.mu.Lock()
+	if element, ok := c.entries[key]; ok {
+		entry := element.Value.(*cacheEntry[V])
+		age := c.now().Sub(entry.fetchedAt)
+		if age < c.config.TTL+c.config.StaleTTL {
+			c.order.MoveToFront(element)
+			if age >= c.config.TTL && !entry.refreshing {
+				entry.refreshing = true
+				go c.refresh(context.WithoutCancel(ctx), key, load, c.generation)
+			}
+			value := entry.value
+			c.mu.Unlock()
+			return value, nil
+		}
+	}
+
+	pending, ok := c.inflight[key]
+	if !ok {
+		pending = &cacheLoad[V]{done: make(chan struct{})}
+		c.inflight[key] = pending
+		go c.fill(context.WithoutCancel(ctx), key, load, pending, c.generation)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-pending.done:
+		return pending.value, pending.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// fill loads a missing value on behalf of every caller waiting for it. It is detached from
+// the context of the first caller, so that caller giving up does not fail the others.
+func (c *cache[V]) fill(ctx context.Context, key string, load func(context.Context) (V, error), pending *cacheLoad[V], generation int) {
+	pending.value, pending.err = load(ctx)
+
+	c.mu.Lock()
+	if c.inflight[key] == pending {
+		delete(c.inflight, key)
+	}
+	if pending.err == nil && generation == c.generation {
+		c.store(key, pending.value)
+	}
+	c.mu.Unlock()
+
+	close(pending.done)
+}
+
+func (c *cache[V]) refresh(ctx context.Context, key string, load func(context.Context) (V, error), generation int) {
+	value, err := load(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	if err != nil {
+		slog.Warn("Error refreshing cached Optii response, serving the stale one", "key", key, "error", err)
+		if element, ok := c.entries[key]; ok {
+			element.Value.(*cacheEntry[V]).refreshing = false
+		}
+		return
+	}
+	c.store(key, value)
+}
+
+// store saves the value, evicting the least recently used entries beyond MaxEntries. It must be called with mu held.
+func (c *cache[V]) store(key string, value V) {
+	if element, ok := c.entries[key]; ok {
+		element.Value = &cacheEntry[V]{key: key, value: value, fetchedAt: c.now()}
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry[V]{key: key, value: value, fetchedAt: c.now()})
+	for c.config.MaxEntries > 0 && c.order.Len() > c.config.MaxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry[V]).key)
+	}
+}
+
+func (c *cache[V]) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+	c.inflight = make(map[string]*cacheLoad[V])
+	c.generation++
+}
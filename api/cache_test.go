@@ -0,0 +1,179 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"optii/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// departmentsApi answers GetDepartment with a department named after the number of calls made so far.
+type departmentsApi struct {
+	OptiiApi
+	calls   int32
+	err     error
+	release chan struct{}
+}
+
+func (a *departmentsApi) GetDepartment(ctx context.Context, id int) (*models.Department, error) {
+	n := atomic.AddInt32(&a.calls, 1)
+	if a.release != nil {
+		<-a.release
+	}
+	if a.err != nil {
+		return nil, a.err
+	}
+	return &models.Department{Id: id, Name: time.Duration(n).String()}, nil
+}
+
+func (a *departmentsApi) callCount() int {
+	return int(atomic.LoadInt32(&a.calls))
+}
+
+func newTestCache(next OptiiApi, config CacheConfig) (*cachedOptiiApi, *time.Time) {
+	now := time.Now()
+	cached := NewCachedOptiiApi(next, CachePolicy{Departments: config}).(*cachedOptiiApi)
+	cached.department.now = func() time.Time { return now }
+	return cached, &now
+}
+
+func TestCacheServesFreshResponses(t *testing.T) {
+	next := &departmentsApi{}
+	cached, now := newTestCache(next, CacheConfig{TTL: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		department, err := cached.GetDepartment(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "1ns", department.Name)
+	}
+	assert.Equal(t, 1, next.callCount())
+
+	*now = now.Add(time.Minute)
+	department, _ := cached.GetDepartment(context.Background(), 1)
+	assert.Equal(t, "2ns", department.Name)
+	assert.Equal(t, 2, next.callCount())
+}
+
+func TestCacheServesStaleWhileRevalidating(t *testing.T) {
+	next := &departmentsApi{}
+	cached, now := newTestCache(next, CacheConfig{TTL: time.Minute, StaleTTL: time.Minute})
+
+	cached.GetDepartment(context.Background(), 1)
+	*now = now.Add(time.Minute + time.Second)
+
+	department, err := cached.GetDepartment(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "1ns", department.Name)
+
+	assert.Eventually(t, func() bool {
+		department, _ := cached.GetDepartment(context.Background(), 1)
+		return department.Name == "2ns"
+	}, time.Second, time.Millisecond*5)
+	assert.Equal(t, 2, next.callCount())
+}
+
+func TestCacheDoesNotKeepErrors(t *testing.T) {
+	next := &departmentsApi{err: &Error{StatusCode: 404}}
+	cached, _ := newTestCache(next, CacheConfig{TTL: time.Minute})
+
+	_, err := cached.GetDepartment(context.Background(), 1)
+	assert.Error(t, err)
+
+	next.err = nil
+	department, err := cached.GetDepartment(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, department.Id)
+	assert.Equal(t, 2, next.callCount())
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	next := &departmentsApi{}
+	cached, _ := newTestCache(next, CacheConfig{TTL: time.Minute, MaxEntries: 2})
+
+	cached.GetDepartment(context.Background(), 1)
+	cached.GetDepartment(context.Background(), 2)
+	cached.GetDepartment(context.Background(), 1)
+	cached.GetDepartment(context.Background(), 3)
+	assert.Equal(t, 3, next.callCount())
+
+	cached.GetDepartment(context.Background(), 1)
+	assert.Equal(t, 3, next.callCount())
+
+	cached.GetDepartment(context.Background(), 2)
+	assert.Equal(t, 4, next.callCount())
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	next := &departmentsApi{}
+	cached, _ := newTestCache(next, CacheConfig{TTL: time.Minute})
+
+	cached.GetDepartment(context.Background(), 1)
+	cached.Invalidate(Locations)
+	cached.GetDepartment(context.Background(), 1)
+	assert.Equal(t, 1, next.callCount())
+
+	cached.Invalidate(Departments)
+	cached.GetDepartment(context.Background(), 1)
+	assert.Equal(t, 2, next.callCount())
+
+	cached.Invalidate()
+	cached.GetDepartment(context.Background(), 1)
+	assert.Equal(t, 3, next.callCount())
+}
+
+func TestCacheSharesConcurrentMisses(t *testing.T) {
+	next := &departmentsApi{release: make(chan struct{})}
+	cached, _ := newTestCache(next, CacheConfig{TTL: time.Minute})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			department, err := cached.GetDepartment(context.Background(), 1)
+			assert.NoError(t, err)
+			assert.Equal(t, "1ns", department.Name)
+		}()
+	}
+	assert.Eventually(t, func() bool { return next.callCount() == 1 }, time.Second, time.Millisecond)
+	close(next.release)
+	wg.Wait()
+
+	assert.Equal(t, 1, next.callCount())
+}
+
+func TestCacheWaitHonoursCallerContext(t *testing.T) {
+	next := &departmentsApi{release: make(chan struct{})}
+	defer close(next.release)
+	cached, _ := newTestCache(next, CacheConfig{TTL: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+
+	_, err := cached.GetDepartment(ctx, 1)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestCacheCanBeDisabled(t *testing.T) {
+	next := &departmentsApi{}
+	cached, _ := newTestCache(next, CacheConfig{})
+
+	cached.GetDepartment(context.Background(), 1)
+	cached.GetDepartment(context.Background(), 1)
+	assert.Equal(t, 2, next.callCount())
+}
+
+func TestParamsKeyIgnoresOrder(t *testing.T) {
+	assert.Equal(t,
+		paramsKey(map[string]string{"displayName": "Floor 1", "first": "100"}),
+		paramsKey(map[string]string{"first": "100", "displayName": "Floor 1"}))
+	assert.NotEqual(t,
+		paramsKey(map[string]string{"first": "100"}),
+		paramsKey(map[string]string{"first": "100", "next": "100"}))
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxErrorBody bounds how much of an error response is read.
+const maxErrorBody = 64 * 1024
+
+// Error is returned for every Optii response outside the 2xx range.
+// Code and Message are taken from the response body when Optii sends them.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Method     string
+	URL        string
+	RequestId  string
+	// RetryAfter is how long Optii asked to wait before sending the request again.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "optii %s %s failed with status %d", e.Method, e.URL, e.StatusCode)
+	if e.Code != "" {
+		fmt.Fprintf(&b, " (%s)", e.Code)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&b, ": %s", e.Message)
+	}
+	if e.RequestId != "" {
+		fmt.Fprintf(&b, " [request id %s]", e.RequestId)
+	}
+	return b.String()
+}
+
+// IsClientError reports whether Optii rejected the request itself rather than failing to process it.
+func (e *Error) IsClientError() bool {
+	return e.StatusCode >= 400 && e.StatusCode < 500
+}
+
+// notSentError wraps the failures that happened before the request was sent, such as getting a token.
+type notSentError struct {
+	err error
+}
+
+func (e *notSentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *notSentError) Unwrap() error {
+	return e.err
+}
+
+// NotSent reports whether the call failed before Optii received its request: no token could be had, the rate
+// limiter gave up or the connection could not be opened. Such a call created nothing in Optii.
+func NotSent(err error) bool {
+	var notSent *notSentError
+	if errors.As(err, &notSent) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// newError builds an Error from a failed response and closes its body.
+func newError(resp *http.Response) *Error {
+	defer resp.Body.Close()
+
+	e := &Error{
+		StatusCode: resp.StatusCode,
+		RequestId:  firstHeader(resp.Header, "X-Request-Id", "X-Correlation-Id", "Request-Id"),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+	if resp.Request != nil {
+		e.Method = resp.Request.Method
+		e.URL = resp.Request.URL.Redacted()
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err != nil || len(body) == 0 {
+		return e
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		e.Message = strings.TrimSpace(string(body))
+		return e
+	}
+
+	e.Code = firstString(fields, "code", "errorCode", "error")
+	e.Message = firstString(fields, "message", "error_description", "detail", "title")
+	if e.RequestId == "" {
+		e.RequestId = firstString(fields, "requestId", "traceId")
+	}
+	if e.Message == "" && e.Code == "" {
+		e.Message = strings.TrimSpace(string(body))
+	}
+	return e
+}
+
+func firstHeader(header http.Header, keys ...string) string {
+	for _, key := range keys {
+		if value := header.Get(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func firstString(fields map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		switch value := fields[key].(type) {
+		case string:
+			if value != "" {
+				return value
+			}
+		case float64:
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
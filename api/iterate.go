@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"strconv"
+
+	"optii/models"
+)
+
+// DefaultPageSize is the number of items the iterators below request per page.
+const DefaultPageSize = 100
+
+// PageFetcher fetches up to first items following the cursor next. The first page is fetched with next zero.
+type PageFetcher[T any] func(ctx context.Context, first, next int) (*models.PagedResponse[T], error)
+
+// Iterator walks through every item of a paged Optii listing, fetching the next page only when
+// the items of the current one are used up. Stopping early leaves the remaining pages unfetched.
+//
+//	it := api.Iterate(ctx, api.DefaultPageSize, fetch)
+//	for it.Next() {
+//		item := it.Item()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type Iterator[T any] struct {
+	ctx      context.Context
+	pageSize int
+	fetch    PageFetcher[T]
+
+	items []T
+	index int
+	next  int
+	done  bool
+	err   error
+}
+
+// Iterate returns an iterator over the pages returned by fetch.
+func Iterate[T any](ctx context.Context, pageSize int, fetch PageFetcher[T]) *Iterator[T] {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	return &Iterator[T]{ctx: ctx, pageSize: pageSize, fetch: fetch, index: -1}
+}
+
+// Next advances to the next item, fetching the next page when needed.
+// It returns false once every item was returned or a page could not be fetched.
+func (it *Iterator[T]) Next() bool {
+	for {
+		if it.index+1 < len(it.items) {
+			it.index++
+			return true
+		}
+		if it.done || it.err != nil {
+			return false
+		}
+		it.fetchPage()
+	}
+}
+
+// Item returns the current item.
+func (it *Iterator[T]) Item() T {
+	return it.items[it.index]
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+func (it *Iterator[T]) fetchPage() {
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return
+	}
+
+	page, err := it.fetch(it.ctx, it.pageSize, it.next)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	it.items, it.index = nil, -1
+	if page == nil {
+		it.done = true
+		return
+	}
+	it.items = page.Items
+
+	// A cursor that does not move would return the same page forever.
+	if !page.PageInfo.HasNextPage || page.PageInfo.EndCursor == it.next {
+		it.done = true
+	}
+	it.next = page.PageInfo.EndCursor
+}
+
+// Collect drains the iterator into a slice.
+func Collect[T any](it *Iterator[T]) ([]T, error) {
+	var items []T
+	for it.Next() {
+		items = append(items, it.Item())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// IterateDepartments iterates over the departments whose display name contains displayName, or all of them.
+func IterateDepartments(ctx context.Context, client OptiiApi, displayName string) *Iterator[models.Department] {
+	return Iterate(ctx, DefaultPageSize, func(ctx context.Context, first, next int) (*models.Departments, error) {
+		return client.GetDepartments(ctx, displayName, first, next)
+	})
+}
+
+// IterateLocations iterates over the locations matching the given query parameters.
+// The first and next parameters are set by the iterator.
+func IterateLocations(ctx context.Context, client OptiiApi, params map[string]string) *Iterator[models.Location] {
+	return Iterate(ctx, DefaultPageSize, func(ctx context.Context, first, next int) (*models.Locations, error) {
+		return client.GetLocations(ctx, pageParams(params, first, next))
+	})
+}
+
+// IterateLocationTypes iterates over every location type.
+func IterateLocationTypes(ctx context.Context, client OptiiApi) *Iterator[models.LocationType] {
+	return Iterate(ctx, DefaultPageSize, func(ctx context.Context, first, next int) (*models.LocationTypes, error) {
+		return client.GetLocationTypes(ctx, first, next)
+	})
+}
+
+// IterateJobItems iterates over the job items whose display name contains displayName, or all of them.
+func IterateJobItems(ctx context.Context, client OptiiApi, displayName string) *Iterator[models.JobItem] {
+	return Iterate(ctx, DefaultPageSize, func(ctx context.Context, first, next int) (*models.JobItems, error) {
+		return client.GetJobItems(ctx, first, next, displayName)
+	})
+}
+
+// IterateJobs iterates over the jobs matching the given query parameters.
+// The first and next parameters are set by the iterator.
+func IterateJobs(ctx context.Context, client OptiiApi, params map[string]string) *Iterator[models.Job] {
+	return Iterate(ctx, DefaultPageSize, func(ctx context.Context, first, next int) (*models.Jobs, error) {
+		return client.GetJobs(ctx, pageParams(params, first, next))
+	})
+}
+
+// pageParams copies params and adds the paging parameters to it.
+func pageParams(params map[string]string, first, next int) map[string]string {
+	paged := make(map[string]string, len(params)+2)
+	for key, value := range params {
+		paged[key] = value
+	}
+	paged["first"] = strconv.Itoa(first)
+	if next > 0 {
+		paged["next"] = strconv.Itoa(next)
+	}
+	return paged
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"optii/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pages serves the given items in pages of the requested size, using item positions as cursors.
+func pages[T any](items []T) PageFetcher[T] {
+	return func(ctx context.Context, first, next int) (*models.PagedResponse[T], error) {
+		end := next + first
+		if end > len(items) {
+			end = len(items)
+		}
+		return &models.PagedResponse[T]{
+			Items:    items[next:end],
+			PageInfo: models.PageInfo{TotalCount: len(items), EndCursor: end, HasNextPage: end < len(items)},
+		}, nil
+	}
+}
+
+func TestIterateWalksEveryPage(t *testing.T) {
+	items, err := Collect(Iterate(context.Background(), 2, pages([]int{1, 2, 3, 4, 5})))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, items)
+}
+
+func TestIterateFetchesLazily(t *testing.T) {
+	var fetched []int
+	fetch := pages([]int{1, 2, 3, 4, 5})
+	it := Iterate(context.Background(), 2, func(ctx context.Context, first, next int) (*models.PagedResponse[int], error) {
+		fetched = append(fetched, next)
+		return fetch(ctx, first, next)
+	})
+
+	for it.Next() {
+		if it.Item() == 3 {
+			break
+		}
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []int{0, 2}, fetched)
+}
+
+func TestIterateStopsOnError(t *testing.T) {
+	failure := errors.New("page unavailable")
+	fetch := pages([]int{1, 2, 3, 4})
+	it := Iterate(context.Background(), 2, func(ctx context.Context, first, next int) (*models.PagedResponse[int], error) {
+		if next > 0 {
+			return nil, failure
+		}
+		return fetch(ctx, first, next)
+	})
+
+	items, err := Collect(it)
+	assert.Nil(t, items)
+	assert.Equal(t, failure, err)
+	assert.False(t, it.Next())
+}
+
+func TestIterateStopsWhenCursorDoesNotMove(t *testing.T) {
+	calls := 0
+	it := Iterate(context.Background(), 2, func(ctx context.Context, first, next int) (*models.PagedResponse[int], error) {
+		calls++
+		return &models.PagedResponse[int]{Items: []int{1}, PageInfo: models.PageInfo{EndCursor: 7, HasNextPage: true}}, nil
+	})
+
+	items, err := Collect(it)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 1}, items)
+	assert.Equal(t, 2, calls)
+}
+
+func TestIterateHonoursCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Collect(Iterate(ctx, 2, pages([]int{1, 2})))
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestIterateLocationsSendsCursor(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "Floor", query.Get("displayName"))
+		assert.Equal(t, strconv.Itoa(DefaultPageSize), query.Get("first"))
+
+		switch query.Get("next") {
+		case "":
+			json.NewEncoder(w).Encode(models.Locations{
+				Items:    []models.Location{{Id: 1}},
+				PageInfo: models.PageInfo{EndCursor: 10, HasNextPage: true},
+			})
+		case "10":
+			json.NewEncoder(w).Encode(models.Locations{
+				Items:    []models.Location{{Id: 2}},
+				PageInfo: models.PageInfo{EndCursor: 20},
+			})
+		default:
+			t.Errorf("unexpected cursor %s", query.Get("next"))
+		}
+	})
+
+	params := map[string]string{"displayName": "Floor"}
+	locations, err := Collect(IterateLocations(context.Background(), server.client(), params))
+	assert.NoError(t, err)
+	assert.Len(t, locations, 2)
+	assert.Equal(t, map[string]string{"displayName": "Floor"}, params)
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultRateLimit is the number of requests per second sent to Optii unless WithRateLimit is given.
+	DefaultRateLimit = 10
+	// DefaultRateBurst is the number of requests that may be sent at once after a quiet period.
+	DefaultRateBurst = 20
+)
+
+// WithRateLimit limits the requests sent to Optii to the given number per second, allowing bursts of up to burst
+// requests. Every attempt counts, including retries. A zero or negative limit disables rate limiting.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(s *optiiApi) {
+		s.limiter = newRateLimiter(perSecond, burst)
+	}
+}
+
+// RateLimitStats describes how long requests waited for the rate limiter.
+type RateLimitStats struct {
+	// Requests is the number of requests that passed the limiter.
+	Requests int64 `json:"requests"`
+	// Delayed is the number of those requests that had to wait.
+	Delayed int64 `json:"delayed"`
+	// WaitTime is the time all requests spent waiting.
+	WaitTime time.Duration `json:"wait_time" swaggertype:"integer"`
+	// MaxWait is the longest single wait.
+	MaxWait time.Duration `json:"max_wait" swaggertype:"integer"`
+	// Rejected is the number of requests whose deadline would pass before their turn.
+	Rejected int64 `json:"rejected"`
+}
+
+// RateLimitedOptiiApi is an OptiiApi whose rate limiter metrics can be read.
+type RateLimitedOptiiApi interface {
+	OptiiApi
+	// RateLimitStats returns the rate limiter metrics of the client since it was created.
+	RateLimitStats() RateLimitStats
+}
+
+// RateLimitStats returns the rate limiter metrics of the client since it was created.
+func (s *optiiApi) RateLimitStats() RateLimitStats {
+	if s.limiter == nil {
+		return RateLimitStats{}
+	}
+	return s.limiter.stats()
+}
+
+// rateLimiter is a token bucket shared by every call of the client. Callers reserve a token up front
+// and wait until it is due, so concurrent callers are served in order instead of all polling.
+type rateLimiter struct {
+	perSecond float64
+	burst     float64
+	now       func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	requests int64
+	delayed  int64
+	rejected int64
+	waitTime int64
+	maxWait  int64
+}
+
+func newRateLimiter(perSecond float64, burst int) *rateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		perSecond: perSecond,
+		burst:     float64(burst),
+		tokens:    float64(burst),
+		now:       time.Now,
+	}
+}
+
+// Wait blocks until the caller may send a request. It fails at once when ctx would expire first.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	delay := l.reserve()
+	if delay <= 0 {
+		l.record(0)
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		l.cancel()
+		atomic.AddInt64(&l.rejected, 1)
+		return fmt.Errorf("rate limit wait of %s exceeds the deadline: %w", delay, context.DeadlineExceeded)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		l.record(delay)
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+		atomic.AddInt64(&l.rejected, 1)
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token and returns how long the caller has to wait until it is available.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.perSecond
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.perSecond * float64(time.Second))
+}
+
+// cancel returns a reserved token the caller did not use.
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+func (l *rateLimiter) record(wait time.Duration) {
+	atomic.AddInt64(&l.requests, 1)
+	if wait <= 0 {
+		return
+	}
+	atomic.AddInt64(&l.delayed, 1)
+	atomic.AddInt64(&l.waitTime, int64(wait))
+	for {
+		max := atomic.LoadInt64(&l.maxWait)
+		if int64(wait) <= max || atomic.CompareAndSwapInt64(&l.maxWait, max, int64(wait)) {
+			return
+		}
+	}
+}
+
+func (l *rateLimiter) stats() RateLimitStats {
+	return RateLimitStats{
+		Requests: atomic.LoadInt64(&l.requests),
+		Delayed:  atomic.LoadInt64(&l.delayed),
+		WaitTime: time.Duration(atomic.LoadInt64(&l.waitTime)),
+		MaxWait:  time.Duration(atomic.LoadInt64(&l.maxWait)),
+		Rejected: atomic.LoadInt64(&l.rejected),
+	}
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"optii/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterAllowsBurst(t *testing.T) {
+	now := time.Now()
+	limiter := newRateLimiter(10, 3)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, time.Duration(0), limiter.reserve())
+	}
+	assert.Equal(t, time.Millisecond*100, limiter.reserve())
+	assert.Equal(t, time.Millisecond*200, limiter.reserve())
+
+	now = now.Add(time.Second)
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+}
+
+func TestRateLimiterRefillIsCappedByBurst(t *testing.T) {
+	now := time.Now()
+	limiter := newRateLimiter(10, 2)
+	limiter.now = func() time.Time { return now }
+
+	limiter.reserve()
+	now = now.Add(time.Hour)
+
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+	assert.Equal(t, time.Duration(0), limiter.reserve())
+	assert.Equal(t, time.Millisecond*100, limiter.reserve())
+}
+
+func TestRateLimiterRejectsWaitBeyondDeadline(t *testing.T) {
+	limiter := newRateLimiter(1, 1)
+	assert.NoError(t, limiter.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	err := limiter.Wait(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, int64(1), limiter.stats().Rejected)
+
+	// The rejected caller gave its token back, so the next one waits no longer than before.
+	assert.LessOrEqual(t, limiter.reserve(), time.Second)
+}
+
+func TestRateLimitIsSharedByConcurrentCalls(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.Location{Id: 1})
+	})
+	client := NewOptiiApi(server.URL, "id", "secret", server.URL+"/token", WithRateLimit(50, 5))
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetLocation(context.Background(), 1)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// Five requests pass at once, the other five are spaced 20ms apart.
+	assert.GreaterOrEqual(t, time.Since(start), time.Millisecond*90)
+
+	stats := client.RateLimitStats()
+	assert.Equal(t, int64(10), stats.Requests)
+	assert.Equal(t, int64(5), stats.Delayed)
+	assert.GreaterOrEqual(t, stats.MaxWait, time.Millisecond*90)
+	assert.GreaterOrEqual(t, stats.WaitTime, stats.MaxWait)
+}
+
+func TestRateLimitCanBeDisabled(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(models.Location{Id: 1})
+	})
+	client := NewOptiiApi(server.URL, "id", "secret", server.URL+"/token", WithRateLimit(0, 0))
+
+	_, err := client.GetLocation(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, RateLimitStats{}, client.RateLimitStats())
+}
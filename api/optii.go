@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -13,115 +14,190 @@ import (
 	"optii/models"
 )
 
+// OptiiApi is the client of the Optii open API.
+// Every call is bound to the given context and to the request timeout of the client, whichever ends first.
+// Transient failures are retried within that deadline according to the RetryPolicy of the client.
 type OptiiApi interface {
-	GetDepartment(id int) (*models.Department, error)
-	GetDepartments(displayName string, first, next int) (*models.Departments, error)
-	GetLocation(locationId int) (*models.Location, error)
-	GetLocations(params map[string]string) (*models.Locations, error)
-	GetLocationTypes() (*models.LocationTypes, error)
-	GetLocationType(locationTypeId int) (*models.LocationType, error)
-	GetJobItem(jobItemId int) (*models.JobItem, error)
-	GetJobItems(first int, next int, displayName string) (*models.JobItems, error)
-	GetJob(jobId int) (*models.Job, error)
-	GetJobs(params map[string]string) (*models.Jobs, error)
-	CreateJob(jobData *models.Job) (*models.Job, error)
+	GetDepartment(ctx context.Context, id int) (*models.Department, error)
+	GetDepartments(ctx context.Context, displayName string, first, next int) (*models.Departments, error)
+	GetLocation(ctx context.Context, locationId int) (*models.Location, error)
+	GetLocations(ctx context.Context, params map[string]string) (*models.Locations, error)
+	GetLocationTypes(ctx context.Context, first, next int) (*models.LocationTypes, error)
+	GetLocationType(ctx context.Context, locationTypeId int) (*models.LocationType, error)
+	GetJobItem(ctx context.Context, jobItemId int) (*models.JobItem, error)
+	GetJobItems(ctx context.Context, first int, next int, displayName string) (*models.JobItems, error)
+	GetJob(ctx context.Context, jobId int) (*models.Job, error)
+	GetJobs(ctx context.Context, params map[string]string) (*models.Jobs, error)
+	CreateJob(ctx context.Context, jobData *models.Job) (*models.Job, error)
+	UpdateJob(ctx context.Context, jobId int, patch *models.JobPatch) (*models.Job, error)
+	TransitionJob(ctx context.Context, jobId int, status models.JobStatus) (*models.Job, error)
+	CancelJob(ctx context.Context, jobId int, reason string) (*models.Job, error)
+	AddJobNote(ctx context.Context, jobId int, note string) (*models.Notes, error)
 }
 
+const DefaultRequestTimeout = time.Second * 30
+
 type optiiApi struct {
-	httpClient   *http.Client
-	url          string
-	clientSecret string
-	clientId     string
-	bearer       string
-	authUrl      string
-	bearerExpiry time.Time
+	httpClient     *http.Client
+	url            string
+	clientSecret   string
+	clientId       string
+	authUrl        string
+	tokens         *tokenManager
+	requestTimeout time.Duration
+	retry          RetryPolicy
+	limiter        *rateLimiter
+}
+
+// Option customises the Optii client created by NewOptiiApi.
+type Option func(*optiiApi)
+
+// WithRequestTimeout sets the deadline applied to every call, including authentication.
+// A zero or negative timeout leaves calls bound only to their context.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(s *optiiApi) {
+		s.requestTimeout = timeout
+	}
+}
+
+func NewOptiiApi(url, clientId, clientSecret, authUrl string, opts ...Option) *optiiApi {
+	s := &optiiApi{
+		httpClient:     &http.Client{},
+		url:            url,
+		authUrl:        authUrl,
+		clientSecret:   clientSecret,
+		clientId:       clientId,
+		requestTimeout: DefaultRequestTimeout,
+		retry:          DefaultRetryPolicy,
+		limiter:        newRateLimiter(DefaultRateLimit, DefaultRateBurst),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.tokens = newTokenManager(s.GetBearer, s.requestTimeout)
+	return s
 }
 
-func NewOptiiApi(url, clientId, clientSecret, authUrl string) *optiiApi {
-	return &optiiApi{
-		httpClient:   &http.Client{Timeout: time.Second * 30},
-		url:          url,
-		authUrl:      authUrl,
-		clientSecret: clientSecret,
-		clientId:     clientId,
+// withTimeout bounds ctx by the request timeout of the client.
+func (s *optiiApi) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.requestTimeout <= 0 {
+		return context.WithCancel(ctx)
 	}
+	return context.WithTimeout(ctx, s.requestTimeout)
 }
 
-func (s *optiiApi) GetBearer() error {
+// GetBearer requests a new access token, returning it together with its lifetime from expires_in.
+// The lifetime is zero when the authentication server does not send one.
+func (s *optiiApi) GetBearer(ctx context.Context) (string, time.Duration, error) {
 	data := url.Values{}
 	data.Set("client_id", s.clientId)
 	data.Set("client_secret", s.clientSecret)
 	data.Set("grant_type", "client_credentials")
 	data.Set("scope", "openapi")
 
-	req, err := http.NewRequest("POST", s.authUrl, strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", s.authUrl, strings.NewReader(data.Encode()))
 	if err != nil {
-		return err
+		return "", 0, err
 	}
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return err
+		return "", 0, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to get bearer token, status code: %d", resp.StatusCode)
+		return "", 0, newError(resp)
 	}
 
-	var result map[string]interface{}
+	var result struct {
+		AccessToken string      `json:"access_token"`
+		ExpiresIn   json.Number `json:"expires_in"`
+	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return err
+		return "", 0, err
 	}
 
-	if token, ok := result["access_token"].(string); ok {
-		s.bearer = token
-	} else {
-		return fmt.Errorf("access token missing in response")
+	if result.AccessToken == "" {
+		return "", 0, fmt.Errorf("access token missing in response")
 	}
 
-	return nil
+	var lifetime time.Duration
+	if seconds, err := result.ExpiresIn.Float64(); err == nil && seconds > 0 {
+		lifetime = time.Duration(seconds * float64(time.Second))
+	}
+
+	return result.AccessToken, lifetime, nil
 }
 
+// doRequest sends the request with the current bearer token as soon as the rate limiter lets it through.
+// When the API rejects the token, it is refreshed and the request is sent once more with its body rewound.
+// Responses outside the 2xx range are returned as *Error.
 func (s *optiiApi) doRequest(req *http.Request, retry bool) (*http.Response, error) {
-	if s.bearer == "" || time.Now().After(s.bearerExpiry) {
-		if err := s.GetBearer(); err != nil {
-			return nil, err
-		}
+	token, err := s.tokens.Token(req.Context())
+	if err != nil {
+		return nil, &notSentError{err: err}
 	}
 
-	req.Header.Add("Authorization", "Bearer "+s.bearer)
-	req.Header.Add("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	if err := s.limiter.Wait(req.Context()); err != nil {
+		return nil, &notSentError{err: err}
+	}
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 
-	switch resp.StatusCode {
-	case http.StatusOK:
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
 		return resp, nil
-	case http.StatusUnauthorized:
-		if retry {
-			resp.Body.Close()
-			return s.doRequest(req, false)
+	case resp.StatusCode == http.StatusUnauthorized && retry:
+		resp.Body.Close()
+		if _, err := s.tokens.Refresh(req.Context(), token); err != nil {
+			// Optii turned the request away, so it was never processed.
+			return nil, &notSentError{err: err}
+		}
+		retryReq, err := rewind(req)
+		if err != nil {
+			return nil, err
 		}
-		return nil, fmt.Errorf("auth failed, status code: %d", resp.StatusCode)
+		return s.doRequest(retryReq, false)
 	default:
-		resp.Body.Close()
-		return nil, fmt.Errorf("request failed, status code: %d", resp.StatusCode)
+		return nil, newError(resp)
+	}
+}
+
+// rewind returns a copy of a sent request whose body can be sent again.
+func rewind(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, fmt.Errorf("request body of %s %s cannot be sent again", req.Method, req.URL)
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		clone.Body = body
 	}
+	return clone, nil
 }
 
-func (s *optiiApi) GetDepartment(departmentId int) (*models.Department, error) {
+func (s *optiiApi) GetDepartment(ctx context.Context, departmentId int) (*models.Department, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	url := fmt.Sprintf("%s/api/v1/departments/%d", s.url, departmentId)
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := s.doRequest(req, true)
+	resp, err := s.send(req)
 	if err != nil {
 		return nil, err
 	}
@@ -135,7 +211,10 @@ func (s *optiiApi) GetDepartment(departmentId int) (*models.Department, error) {
 	return &department, nil
 }
 
-func (s *optiiApi) GetDepartments(displayName string, first, next int) (*models.Departments, error) {
+func (s *optiiApi) GetDepartments(ctx context.Context, displayName string, first, next int) (*models.Departments, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	queryParams := url.Values{}
 	if displayName != "" {
 		queryParams.Add("displayName", displayName)
@@ -148,21 +227,17 @@ func (s *optiiApi) GetDepartments(displayName string, first, next int) (*models.
 	}
 	fullURL := fmt.Sprintf("%s/api/v1/departments?%s", s.url, queryParams.Encode())
 
-	req, err := http.NewRequest("GET", fullURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := s.doRequest(req, true)
+	resp, err := s.send(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("response failed, status code: %d", resp.StatusCode)
-	}
-
 	var departments models.Departments
 	if err := json.NewDecoder(resp.Body).Decode(&departments); err != nil {
 		return nil, err
@@ -171,14 +246,17 @@ func (s *optiiApi) GetDepartments(displayName string, first, next int) (*models.
 	return &departments, nil
 }
 
-func (s *optiiApi) GetLocation(locationId int) (*models.Location, error) {
+func (s *optiiApi) GetLocation(ctx context.Context, locationId int) (*models.Location, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	url := fmt.Sprintf("%s/api/v1/locations/%d", s.url, locationId)
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := s.doRequest(req, true)
+	resp, err := s.send(req)
 	if err != nil {
 		return nil, err
 	}
@@ -192,28 +270,27 @@ func (s *optiiApi) GetLocation(locationId int) (*models.Location, error) {
 	return &location, nil
 }
 
-func (s *optiiApi) GetLocations(params map[string]string) (*models.Locations, error) {
+func (s *optiiApi) GetLocations(ctx context.Context, params map[string]string) (*models.Locations, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	queryParams := url.Values{}
 	for key, value := range params {
 		queryParams.Add(key, value)
 	}
 	fullURL := fmt.Sprintf("%s/api/v1/locations?%s", s.url, queryParams.Encode())
 
-	req, err := http.NewRequest("GET", fullURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := s.doRequest(req, true)
+	resp, err := s.send(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("error getting locations, status code: %d", resp.StatusCode)
-	}
-
 	var locations models.Locations
 	if err := json.NewDecoder(resp.Body).Decode(&locations); err != nil {
 		return nil, err
@@ -222,24 +299,30 @@ func (s *optiiApi) GetLocations(params map[string]string) (*models.Locations, er
 	return &locations, nil
 }
 
-func (s *optiiApi) GetLocationTypes() (*models.LocationTypes, error) {
-	url := fmt.Sprintf("%s/api/v1/locationTypes", s.url)
+func (s *optiiApi) GetLocationTypes(ctx context.Context, first, next int) (*models.LocationTypes, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
 
-	req, err := http.NewRequest("GET", url, nil)
+	queryParams := url.Values{}
+	if first > 0 {
+		queryParams.Add("first", strconv.Itoa(first))
+	}
+	if next > 0 {
+		queryParams.Add("next", strconv.Itoa(next))
+	}
+	url := fmt.Sprintf("%s/api/v1/locationTypes?%s", s.url, queryParams.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := s.doRequest(req, true)
+	resp, err := s.send(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("error getting location types, status code: %d", resp.StatusCode)
-	}
-
 	var locationTypes models.LocationTypes
 	if err := json.NewDecoder(resp.Body).Decode(&locationTypes); err != nil {
 		return nil, err
@@ -248,24 +331,23 @@ func (s *optiiApi) GetLocationTypes() (*models.LocationTypes, error) {
 	return &locationTypes, nil
 }
 
-func (s *optiiApi) GetLocationType(locationTypeId int) (*models.LocationType, error) {
+func (s *optiiApi) GetLocationType(ctx context.Context, locationTypeId int) (*models.LocationType, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	url := fmt.Sprintf("%s/api/v1/locationTypes/%d", s.url, locationTypeId)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := s.doRequest(req, true)
+	resp, err := s.send(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("error getting location type, status code: %d", resp.StatusCode)
-	}
-
 	var locationType models.LocationType
 	if err := json.NewDecoder(resp.Body).Decode(&locationType); err != nil {
 		return nil, err
@@ -274,24 +356,23 @@ func (s *optiiApi) GetLocationType(locationTypeId int) (*models.LocationType, er
 	return &locationType, nil
 }
 
-func (s *optiiApi) GetJobItem(jobItemId int) (*models.JobItem, error) {
+func (s *optiiApi) GetJobItem(ctx context.Context, jobItemId int) (*models.JobItem, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	url := fmt.Sprintf("%s/api/v1/jobitems/%d", s.url, jobItemId)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := s.doRequest(req, true)
+	resp, err := s.send(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("error getting job item, status code: %d", resp.StatusCode)
-	}
-
 	var jobItem models.JobItem
 	if err := json.NewDecoder(resp.Body).Decode(&jobItem); err != nil {
 		return nil, err
@@ -300,7 +381,10 @@ func (s *optiiApi) GetJobItem(jobItemId int) (*models.JobItem, error) {
 	return &jobItem, nil
 }
 
-func (s *optiiApi) GetJobItems(first int, next int, displayName string) (*models.JobItems, error) {
+func (s *optiiApi) GetJobItems(ctx context.Context, first int, next int, displayName string) (*models.JobItems, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	queryParams := url.Values{}
 	if first > 0 {
 		queryParams.Add("first", strconv.Itoa(first))
@@ -313,21 +397,17 @@ func (s *optiiApi) GetJobItems(first int, next int, displayName string) (*models
 	}
 	fullURL := fmt.Sprintf("%s/api/v1/jobitems?%s", s.url, queryParams.Encode())
 
-	req, err := http.NewRequest("GET", fullURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := s.doRequest(req, true)
+	resp, err := s.send(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("error getting job items, status code: %d", resp.StatusCode)
-	}
-
 	var jobItems models.JobItems
 	if err := json.NewDecoder(resp.Body).Decode(&jobItems); err != nil {
 		return nil, err
@@ -336,14 +416,17 @@ func (s *optiiApi) GetJobItems(first int, next int, displayName string) (*models
 	return &jobItems, nil
 }
 
-func (s *optiiApi) GetJob(jobId int) (*models.Job, error) {
+func (s *optiiApi) GetJob(ctx context.Context, jobId int) (*models.Job, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	url := fmt.Sprintf("%s/api/v1/jobs/%d", s.url, jobId)
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := s.doRequest(req, true)
+	resp, err := s.send(req)
 	if err != nil {
 		return nil, err
 	}
@@ -357,19 +440,22 @@ func (s *optiiApi) GetJob(jobId int) (*models.Job, error) {
 	return &job, nil
 }
 
-func (s *optiiApi) GetJobs(params map[string]string) (*models.Jobs, error) {
+func (s *optiiApi) GetJobs(ctx context.Context, params map[string]string) (*models.Jobs, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
 	queryParams := url.Values{}
 	for key, value := range params {
 		queryParams.Add(key, value)
 	}
 	fullURL := fmt.Sprintf("%s/api/v1/jobs?%s", s.url, queryParams.Encode())
 
-	req, err := http.NewRequest("GET", fullURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := s.doRequest(req, true)
+	resp, err := s.send(req)
 	if err != nil {
 		return nil, err
 	}
@@ -383,28 +469,96 @@ func (s *optiiApi) GetJobs(params map[string]string) (*models.Jobs, error) {
 	return &jobs, nil
 }
 
-func (s *optiiApi) CreateJob(jobData *models.Job) (*models.Job, error) {
-	jsonData, err := json.Marshal(jobData)
-	if err != nil {
+func (s *optiiApi) CreateJob(ctx context.Context, jobData *models.Job) (*models.Job, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/api/v1/jobs", s.url)
+	var job models.Job
+	if err := s.sendJSON(ctx, "POST", url, jobData, &job); err != nil {
 		return nil, err
 	}
 
-	url := fmt.Sprintf("%s/api/v1/jobs", s.url)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
+	return &job, nil
+}
+
+// UpdateJob changes the fields set in the patch and leaves the others as they are.
+func (s *optiiApi) UpdateJob(ctx context.Context, jobId int, patch *models.JobPatch) (*models.Job, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/api/v1/jobs/%d", s.url, jobId)
+	var job models.Job
+	if err := s.sendJSON(ctx, "PATCH", url, patch, &job); err != nil {
 		return nil, err
 	}
 
-	resp, err := s.doRequest(req, true)
-	if err != nil {
+	return &job, nil
+}
+
+// TransitionJob moves the job to the given status. Optii rejects transitions its workflow does not allow.
+func (s *optiiApi) TransitionJob(ctx context.Context, jobId int, status models.JobStatus) (*models.Job, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/api/v1/jobs/%d/status", s.url, jobId)
+	body := map[string]models.JobStatus{"status": status}
+	var job models.Job
+	if err := s.sendJSON(ctx, "POST", url, body, &job); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
+	return &job, nil
+}
+
+func (s *optiiApi) CancelJob(ctx context.Context, jobId int, reason string) (*models.Job, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/api/v1/jobs/%d/cancel", s.url, jobId)
+	body := map[string]string{"reason": reason}
 	var job models.Job
-	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+	if err := s.sendJSON(ctx, "POST", url, body, &job); err != nil {
 		return nil, err
 	}
 
 	return &job, nil
 }
+
+func (s *optiiApi) AddJobNote(ctx context.Context, jobId int, note string) (*models.Notes, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/api/v1/jobs/%d/notes", s.url, jobId)
+	var created models.Notes
+	if err := s.sendJSON(ctx, "POST", url, models.Notes{Note: note}, &created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// sendJSON sends body as JSON and decodes the response into result. Requests carrying an idempotency key
+// from the context send it along, which lets them be retried.
+func (s *optiiApi) sendJSON(ctx context.Context, method, url string, body, result interface{}) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	if key, ok := IdempotencyKey(ctx); ok {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+
+	resp, err := s.send(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
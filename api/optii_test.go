@@ -0,0 +1,232 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"optii/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testServer struct {
+	*httptest.Server
+	tokenRequests int32
+}
+
+// newTestServer serves the OAuth token endpoint on /token and the given handler on everything else.
+// Every token it hands out is numbered, starting with token-1.
+func newTestServer(t *testing.T, handler http.HandlerFunc) *testServer {
+	server := &testServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&server.tokenRequests, 1)
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": fmt.Sprintf("token-%d", n), "expires_in": 3600})
+	})
+	mux.HandleFunc("/", handler)
+
+	server.Server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (s *testServer) client(opts ...Option) OptiiApi {
+	return NewOptiiApi(s.URL, "id", "secret", s.URL+"/token", opts...)
+}
+
+func TestRequestTimeout(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	})
+
+	client := server.client(WithRequestTimeout(20 * time.Millisecond))
+
+	_, err := client.GetDepartment(context.Background(), 1)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestRequestCancellation(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+
+	client := server.client()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	_, err := client.GetLocations(ctx, map[string]string{"displayName": "Room 101"})
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestBearerTokenIsReused(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token-1", r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(models.Department{Id: 1})
+	})
+
+	client := server.client()
+	for i := 0; i < 3; i++ {
+		_, err := client.GetDepartment(context.Background(), 1)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&server.tokenRequests))
+}
+
+func TestUnauthorizedRefreshesTokenAndResendsBody(t *testing.T) {
+	var attempts int32
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `"Sheets"`, string(mustField(t, body, "item", "name")))
+
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "Bearer token-2", r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(models.Job{Id: 42})
+	})
+
+	job, err := server.client().CreateJob(context.Background(), &models.Job{Item: models.Item{Name: "Sheets"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 42, job.Id)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&server.tokenRequests))
+}
+
+func TestUnauthorizedIsRetriedOnlyOnce(t *testing.T) {
+	var attempts int32
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	_, err := server.client().GetJob(context.Background(), 1)
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+}
+
+// mustField returns the raw JSON found at the given path of a JSON object.
+func mustField(t *testing.T, data []byte, path ...string) json.RawMessage {
+	raw := json.RawMessage(data)
+	for _, key := range path {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &object); err != nil {
+			t.Fatal(err)
+		}
+		raw = object[key]
+	}
+	return raw
+}
+
+func TestErrorResponseIsCaptured(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"code": "INVALID_LOCATION", "message": "location 10 is inactive"}`))
+	})
+
+	_, err := server.client().CreateJob(context.Background(), &models.Job{})
+
+	var apiErr *Error
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+		assert.Equal(t, "INVALID_LOCATION", apiErr.Code)
+		assert.Equal(t, "location 10 is inactive", apiErr.Message)
+		assert.Equal(t, "POST", apiErr.Method)
+		assert.Equal(t, server.URL+"/api/v1/jobs", apiErr.URL)
+		assert.Equal(t, "req-1", apiErr.RequestId)
+		assert.True(t, apiErr.IsClientError())
+	}
+	assert.EqualError(t, err, "optii POST "+server.URL+"/api/v1/jobs failed with status 422 (INVALID_LOCATION): location 10 is inactive [request id req-1]")
+}
+
+func TestErrorResponseWithPlainBody(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream unavailable", http.StatusBadGateway)
+	})
+
+	_, err := server.client(WithRetryPolicy(RetryPolicy{MaxAttempts: 1})).GetLocationTypes(context.Background(), 0, 0)
+
+	var apiErr *Error
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+		assert.Equal(t, "upstream unavailable", apiErr.Message)
+		assert.False(t, apiErr.IsClientError())
+	}
+}
+
+func TestNotSentFailures(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream unavailable", http.StatusBadGateway)
+	})
+	_, err := server.client().CreateJob(context.Background(), &models.Job{})
+	assert.False(t, NotSent(err), "Optii received the request")
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	_, err = NewOptiiApi(closed.URL, "id", "secret", server.URL+"/token").CreateJob(context.Background(), &models.Job{})
+	assert.True(t, NotSent(err), "the connection was refused")
+
+	_, err = NewOptiiApi(server.URL, "id", "secret", closed.URL+"/token").CreateJob(context.Background(), &models.Job{})
+	assert.True(t, NotSent(err), "no token could be had")
+}
+
+func TestCreatedResponseIsSuccessful(t *testing.T) {
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(models.Job{Id: 7})
+	})
+
+	job, err := server.client().CreateJob(context.Background(), &models.Job{})
+	assert.NoError(t, err)
+	assert.Equal(t, 7, job.Id)
+}
+
+func TestJobChangesAreSentToTheirEndpoints(t *testing.T) {
+	type sent struct {
+		method, path string
+		body         map[string]interface{}
+	}
+	var requests []sent
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, sent{r.Method, r.URL.Path, body})
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 7, "note": "noted"})
+	})
+	client := server.client()
+	ctx := context.Background()
+
+	_, err := client.UpdateJob(ctx, 7, &models.JobPatch{Priority: "high", Assignee: &models.AssigneePatch{EmployeeId: 42}})
+	assert.NoError(t, err)
+	_, err = client.TransitionJob(ctx, 7, models.JobStatusInProgress)
+	assert.NoError(t, err)
+	_, err = client.CancelJob(ctx, 7, "Guest checked out")
+	assert.NoError(t, err)
+	note, err := client.AddJobNote(ctx, 7, "noted")
+	assert.NoError(t, err)
+	assert.Equal(t, "noted", note.Note)
+
+	assert.Equal(t, []sent{
+		{"PATCH", "/api/v1/jobs/7", map[string]interface{}{
+			"priority": "high",
+			"assignee": map[string]interface{}{"employeeId": float64(42)},
+		}},
+		{"POST", "/api/v1/jobs/7/status", map[string]interface{}{"status": "inProgress"}},
+		{"POST", "/api/v1/jobs/7/cancel", map[string]interface{}{"reason": "Guest checked out"}},
+		{"POST", "/api/v1/jobs/7/notes", map[string]interface{}{"note": "noted"}},
+	}, requests)
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// idempotencyKeyHeader carries the key that lets Optii recognise a request it already processed.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// RetryPolicy controls how failed calls are retried. GET requests are retried when Optii answers
+// with 429 or a 5xx status or the connection fails; other requests only when they carry an idempotency key.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a call is sent, including the first one. One or less disables retries.
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry, doubled for every following one.
+	BaseDelay time.Duration
+	// MaxDelay caps the wait between two attempts.
+	MaxDelay time.Duration
+	// Jitter is the fraction of the wait, between 0 and 1, that is randomised to spread retries of concurrent calls.
+	Jitter float64
+}
+
+// DefaultRetryPolicy is used unless WithRetryPolicy is given.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Millisecond * 200,
+	MaxDelay:    time.Second * 5,
+	Jitter:      0.5,
+}
+
+// WithRetryPolicy sets the retry policy of the client.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(s *optiiApi) {
+		s.retry = policy
+	}
+}
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey returns a context whose requests creating data in Optii carry the given idempotency key.
+// Such requests are safe to send again, so they are retried like GET requests.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyKey returns the idempotency key set with WithIdempotencyKey, if any.
+func IdempotencyKey(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKey{}).(string)
+	return key, ok && key != ""
+}
+
+// send sends the request, retrying it according to the retry policy of the client.
+// The waits between attempts count against the deadline of the call.
+func (s *optiiApi) send(req *http.Request) (*http.Response, error) {
+	retryable := req.Method == http.MethodGet || req.Header.Get(idempotencyKeyHeader) != ""
+
+	for attempt := 1; ; attempt++ {
+		resp, err := s.doRequest(req, true)
+		if err == nil || !retryable || attempt >= s.retry.MaxAttempts || !isTransient(req.Context(), err) {
+			return resp, err
+		}
+
+		if !wait(req.Context(), s.retry.delay(attempt, retryAfter(err))) {
+			return nil, err
+		}
+
+		next, rewindErr := rewind(req)
+		if rewindErr != nil {
+			return nil, err
+		}
+		req = next
+	}
+}
+
+// delay returns the wait before the retry following the given attempt. A Retry-After sent by Optii
+// is never shortened.
+func (p RetryPolicy) delay(attempt int, retryAfter time.Duration) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * p.Jitter * float64(delay))
+	}
+	if retryAfter > delay {
+		return retryAfter
+	}
+	return delay
+}
+
+// isTransient reports whether the call may succeed when sent again.
+func isTransient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func retryAfter(err error) time.Duration {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
+
+// wait pauses for the given delay. It returns false without waiting when the deadline of ctx
+// would pass first, so the caller gets the last error instead of a timeout.
+func wait(ctx context.Context, delay time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"optii/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var fastRetries = WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond * 5})
+
+func TestTransientFailuresAreRetried(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable} {
+		var requests int32
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) < 3 {
+				w.WriteHeader(status)
+				return
+			}
+			json.NewEncoder(w).Encode(models.Departments{Items: []models.Department{{Id: 1}}})
+		})
+
+		departments, err := server.client(fastRetries).GetDepartments(context.Background(), "Housekeeping", 10, 0)
+		assert.NoError(t, err, "status %d", status)
+		assert.Len(t, departments.Items, 1)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	}
+}
+
+func TestRetriesGiveUpAfterMaxAttempts(t *testing.T) {
+	var requests int32
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	_, err := server.client(fastRetries).GetLocations(context.Background(), nil)
+	var apiErr *Error
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
+
+func TestClientErrorsAreNotRetried(t *testing.T) {
+	var requests int32
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	_, err := server.client(fastRetries).GetLocation(context.Background(), 1)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestDroppedConnectionIsRetried(t *testing.T) {
+	var requests int32
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			assert.NoError(t, err)
+			conn.Close()
+			return
+		}
+		json.NewEncoder(w).Encode(models.JobItem{Id: 4})
+	})
+
+	item, err := server.client(fastRetries).GetJobItem(context.Background(), 4)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, item.Id)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestCreateJobIsRetriedOnlyWithIdempotencyKey(t *testing.T) {
+	var requests int32
+	var keys []string
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var sent models.Job
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&sent))
+		assert.Equal(t, "Clean", sent.Action)
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(models.Job{Id: 9})
+	})
+	client := server.client(fastRetries)
+	job := &models.Job{Action: "Clean"}
+
+	_, err := client.CreateJob(context.Background(), job)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	created, err := client.CreateJob(WithIdempotencyKey(context.Background(), "key-1"), job)
+	assert.NoError(t, err)
+	assert.Equal(t, 9, created.Id)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	assert.Equal(t, []string{"", "key-1", "key-1"}, keys)
+}
+
+func TestRetryAfterIsRespected(t *testing.T) {
+	var first time.Time
+	var waited time.Duration
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if first.IsZero() {
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		waited = time.Since(first)
+		json.NewEncoder(w).Encode(models.Department{Id: 1})
+	})
+
+	_, err := server.client(fastRetries).GetDepartment(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, waited, time.Second)
+}
+
+func TestRetryAfterBeyondDeadlineIsNotAwaited(t *testing.T) {
+	var requests int32
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	start := time.Now()
+	_, err := server.client(fastRetries, WithRequestTimeout(time.Second)).GetDepartment(context.Background(), 1)
+
+	var apiErr *Error
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, time.Minute, apiErr.RetryAfter)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Millisecond * 100, MaxDelay: time.Second}
+
+	assert.Equal(t, time.Millisecond*100, policy.delay(1, 0))
+	assert.Equal(t, time.Millisecond*200, policy.delay(2, 0))
+	assert.Equal(t, time.Millisecond*800, policy.delay(4, 0))
+	assert.Equal(t, time.Second, policy.delay(5, 0))
+	assert.Equal(t, time.Second, policy.delay(50, 0))
+	assert.Equal(t, time.Second*3, policy.delay(1, time.Second*3))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := policy.delay(2, 0)
+		assert.GreaterOrEqual(t, delay, time.Millisecond*100)
+		assert.LessOrEqual(t, delay, time.Millisecond*200)
+	}
+}
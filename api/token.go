@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultTokenLifetime is assumed when the authentication server does not send expires_in.
+	defaultTokenLifetime = time.Minute * 5
+	// tokenRefreshAhead is how long before its expiry a token is refreshed.
+	tokenRefreshAhead = time.Second * 30
+)
+
+// tokenFetcher requests a new bearer token together with its lifetime.
+type tokenFetcher func(ctx context.Context) (string, time.Duration, error)
+
+// tokenManager hands out the bearer token and refreshes it ahead of its expiry.
+// Concurrent callers that need a new token share a single request to the authentication server.
+type tokenManager struct {
+	fetch   tokenFetcher
+	timeout time.Duration
+	now     func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	refreshAt time.Time
+	inflight  *tokenRefresh
+}
+
+type tokenRefresh struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+func newTokenManager(fetch tokenFetcher, timeout time.Duration) *tokenManager {
+	return &tokenManager{
+		fetch:   fetch,
+		timeout: timeout,
+		now:     time.Now,
+	}
+}
+
+// Token returns the current token, refreshing it first when it is missing or about to expire.
+func (m *tokenManager) Token(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	if m.token != "" && m.now().Before(m.refreshAt) {
+		token := m.token
+		m.mu.Unlock()
+		return token, nil
+	}
+	refresh := m.startRefresh()
+	m.mu.Unlock()
+
+	return refresh.wait(ctx)
+}
+
+// Refresh replaces a token the API rejected. When another caller already replaced it, the new token is returned
+// without asking for another one.
+func (m *tokenManager) Refresh(ctx context.Context, rejected string) (string, error) {
+	m.mu.Lock()
+	if m.token != "" && m.token != rejected {
+		token := m.token
+		m.mu.Unlock()
+		return token, nil
+	}
+	m.token = ""
+	refresh := m.startRefresh()
+	m.mu.Unlock()
+
+	return refresh.wait(ctx)
+}
+
+// startRefresh joins the refresh in flight or starts a new one. It must be called with mu held.
+// The refresh is detached from the caller's context, so one caller giving up does not fail the others.
+func (m *tokenManager) startRefresh() *tokenRefresh {
+	if m.inflight != nil {
+		return m.inflight
+	}
+
+	refresh := &tokenRefresh{done: make(chan struct{})}
+	m.inflight = refresh
+
+	go func() {
+		ctx := context.Background()
+		if m.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, m.timeout)
+			defer cancel()
+		}
+
+		token, lifetime, err := m.fetch(ctx)
+		if lifetime <= 0 {
+			lifetime = defaultTokenLifetime
+		}
+
+		m.mu.Lock()
+		if err == nil {
+			m.token = token
+			m.refreshAt = m.now().Add(lifetime - refreshAhead(lifetime))
+		}
+		m.inflight = nil
+		m.mu.Unlock()
+
+		refresh.token, refresh.err = token, err
+		close(refresh.done)
+	}()
+
+	return refresh
+}
+
+func (r *tokenRefresh) wait(ctx context.Context) (string, error) {
+	select {
+	case <-r.done:
+		return r.token, r.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// refreshAhead keeps short-lived tokens usable for most of their lifetime.
+func refreshAhead(lifetime time.Duration) time.Duration {
+	if ahead := lifetime / 10; ahead < tokenRefreshAhead {
+		return ahead
+	}
+	return tokenRefreshAhead
+}
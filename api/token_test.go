@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenManagerRefreshesAheadOfExpiry(t *testing.T) {
+	var fetches int32
+	manager := newTokenManager(func(ctx context.Context) (string, time.Duration, error) {
+		n := atomic.AddInt32(&fetches, 1)
+		return fmt.Sprintf("token-%d", n), time.Minute * 10, nil
+	}, time.Second)
+
+	now := time.Now()
+	manager.now = func() time.Time { return now }
+
+	token, err := manager.Token(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+
+	now = now.Add(time.Minute * 9)
+	token, _ = manager.Token(context.Background())
+	assert.Equal(t, "token-1", token)
+
+	now = now.Add(time.Second * 40)
+	token, _ = manager.Token(context.Background())
+	assert.Equal(t, "token-2", token)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
+
+func TestTokenManagerSharesConcurrentRefreshes(t *testing.T) {
+	var fetches int32
+	release := make(chan struct{})
+	manager := newTokenManager(func(ctx context.Context) (string, time.Duration, error) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		return "token", time.Hour, nil
+	}, time.Second)
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 10)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], _ = manager.Token(context.Background())
+		}(i)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+	for _, token := range tokens {
+		assert.Equal(t, "token", token)
+	}
+}
+
+func TestTokenManagerRefreshSkipsAlreadyReplacedToken(t *testing.T) {
+	var fetches int32
+	manager := newTokenManager(func(ctx context.Context) (string, time.Duration, error) {
+		n := atomic.AddInt32(&fetches, 1)
+		return fmt.Sprintf("token-%d", n), time.Hour, nil
+	}, time.Second)
+
+	rejected, _ := manager.Token(context.Background())
+
+	token, err := manager.Refresh(context.Background(), rejected)
+	assert.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+
+	token, err = manager.Refresh(context.Background(), rejected)
+	assert.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
@@ -6,11 +6,64 @@ import (
 	"optii/api"
 )
 
-func (i *Infra) SetupOptiiApi() api.OptiiApi {
-	return api.NewOptiiApi(
+// SetupOptiiApi returns the Optii client shared by every service, so they all draw from the same rate limit
+// and reference data cache. The cache is returned as an api.CachedOptiiApi so it can be invalidated.
+func (i *Infra) SetupOptiiApi() api.CachedOptiiApi {
+	if i.optiiApi != nil {
+		return i.optiiApi
+	}
+
+	i.optiiApi = api.NewCachedOptiiApi(i.SetupOptiiClient(), i.SetupOptiiCachePolicy())
+	return i.optiiApi
+}
+
+// SetupOptiiClient returns the Optii client without the reference data cache, for callers that must read what
+// Optii holds now. It shares the rate limit of SetupOptiiApi, whose metrics it reports.
+func (i *Infra) SetupOptiiClient() api.RateLimitedOptiiApi {
+	if i.optiiClient != nil {
+		return i.optiiClient
+	}
+
+	client := api.NewOptiiApi(
 		os.Getenv("OPTII_URL"),
 		os.Getenv("OPTII_CLIENT_ID"),
 		os.Getenv("OPTII_CLIENT_SECRET"),
 		os.Getenv("OPTII_AUTHETICATION_URL"),
+		api.WithRequestTimeout(durationEnv("OPTII_REQUEST_TIMEOUT", api.DefaultRequestTimeout)),
+		api.WithRetryPolicy(i.SetupOptiiRetryPolicy()),
+		api.WithRateLimit(floatEnv("OPTII_RATE_LIMIT", api.DefaultRateLimit), intEnv("OPTII_RATE_BURST", api.DefaultRateBurst)),
 	)
+	i.optiiClient = client
+	return client
+}
+
+// SetupOptiiRetryPolicy reads the retry policy of the Optii client, using api.DefaultRetryPolicy for anything unset.
+func (i *Infra) SetupOptiiRetryPolicy() api.RetryPolicy {
+	defaults := api.DefaultRetryPolicy
+	return api.RetryPolicy{
+		MaxAttempts: intEnv("OPTII_RETRY_MAX_ATTEMPTS", defaults.MaxAttempts),
+		BaseDelay:   durationEnv("OPTII_RETRY_BASE_DELAY", defaults.BaseDelay),
+		MaxDelay:    durationEnv("OPTII_RETRY_MAX_DELAY", defaults.MaxDelay),
+		Jitter:      floatEnv("OPTII_RETRY_JITTER", defaults.Jitter),
+	}
+}
+
+// SetupOptiiCachePolicy reads the reference data cache configuration, using api.DefaultCachePolicy for anything unset.
+// Every kind of reference data is configured with OPTII_CACHE_<KIND>_TTL, _STALE_TTL and _MAX_ENTRIES.
+func (i *Infra) SetupOptiiCachePolicy() api.CachePolicy {
+	defaults := api.DefaultCachePolicy
+	return api.CachePolicy{
+		Departments:   cacheConfig("OPTII_CACHE_DEPARTMENTS", defaults.Departments),
+		JobItems:      cacheConfig("OPTII_CACHE_JOB_ITEMS", defaults.JobItems),
+		Locations:     cacheConfig("OPTII_CACHE_LOCATIONS", defaults.Locations),
+		LocationTypes: cacheConfig("OPTII_CACHE_LOCATION_TYPES", defaults.LocationTypes),
+	}
+}
+
+func cacheConfig(prefix string, defaults api.CacheConfig) api.CacheConfig {
+	return api.CacheConfig{
+		TTL:        durationEnv(prefix+"_TTL", defaults.TTL),
+		StaleTTL:   durationEnv(prefix+"_STALE_TTL", defaults.StaleTTL),
+		MaxEntries: intEnv(prefix+"_MAX_ENTRIES", defaults.MaxEntries),
+	}
 }
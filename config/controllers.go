@@ -3,5 +3,25 @@ package config
 import "optii/controllers"
 
 func (i *Infra) SetupJobController() controllers.JobController {
-	return controllers.NewJobController(i.SetupJobService())
-}
\ No newline at end of file
+	return controllers.NewJobController(i.SetupJobService(), i.SetupOperationService())
+}
+
+func (i *Infra) SetupLocationController() controllers.LocationController {
+	return controllers.NewLocationController(i.SetupLocationService())
+}
+
+func (i *Infra) SetupReferenceController() controllers.ReferenceController {
+	return controllers.NewReferenceController(i.SetupReferenceService())
+}
+
+func (i *Infra) SetupOperationController() controllers.OperationController {
+	return controllers.NewOperationController(i.SetupOperationService())
+}
+
+func (i *Infra) SetupOutboxController() controllers.OutboxController {
+	return controllers.NewOutboxController(i.SetupOutboxService())
+}
+
+func (i *Infra) SetupMetricsController() controllers.MetricsController {
+	return controllers.NewMetricsController(i.SetupOptiiClient())
+}
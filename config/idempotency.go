@@ -0,0 +1,16 @@
+package config
+
+import (
+	"time"
+
+	"optii/controllers"
+	"optii/idempotency"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupIdempotency returns the middleware honouring Idempotency-Key headers. Responses are kept in memory for
+// IDEMPOTENCY_KEY_TTL (default 24h).
+func (i *Infra) SetupIdempotency() gin.HandlerFunc {
+	return controllers.Idempotent(idempotency.NewMemoryStore(durationEnv("IDEMPOTENCY_KEY_TTL", time.Hour*24)))
+}
@@ -1,8 +1,75 @@
 package config
 
-type Infra struct {}
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"optii/api"
+	"optii/locations"
+	"optii/operations"
+	"optii/outbox"
+	"optii/rules"
+	"optii/services"
+)
+
+type Infra struct {
+	optiiClient     api.RateLimitedOptiiApi
+	optiiApi        api.CachedOptiiApi
+	rulesEngine     rules.Engine
+	jobService      services.JobService
+	locationIndex   locations.Index
+	operationRunner operations.Runner
+	outbox          outbox.Outbox
+}
 
 func NewInfra() *Infra {
 	i := &Infra{}
 	return i
 }
+
+// durationEnv parses the environment variable as a time.Duration, falling back when it is unset or invalid.
+func durationEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		slog.Error("Invalid duration, using the default", "key", key, "value", value, "default", fallback)
+		return fallback
+	}
+	return duration
+}
+
+// intEnv parses the environment variable as an int, falling back when it is unset or invalid.
+func intEnv(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		slog.Error("Invalid number, using the default", "key", key, "value", value, "default", fallback)
+		return fallback
+	}
+	return n
+}
+
+// floatEnv parses the environment variable as a float64, falling back when it is unset or invalid.
+func floatEnv(key string, fallback float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		slog.Error("Invalid number, using the default", "key", key, "value", value, "default", fallback)
+		return fallback
+	}
+	return f
+}
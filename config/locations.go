@@ -0,0 +1,37 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"optii/api"
+	"optii/locations"
+)
+
+// SetupLocationIndex returns the location hierarchy shared by every service. It is loaded on first use and
+// reloaded every LOCATIONS_REFRESH_INTERVAL (default 5m), so locations added in Optii become known without a restart.
+// It reads Optii without the reference data cache, which would otherwise hold back changes for up to the locations TTL,
+// and drops the cached locations after every reload so job requests see the same locations as the index.
+func (i *Infra) SetupLocationIndex() locations.Index {
+	if i.locationIndex != nil {
+		return i.locationIndex
+	}
+
+	index := locations.NewIndex(i.SetupOptiiClient())
+
+	if interval := durationEnv("LOCATIONS_REFRESH_INTERVAL", time.Minute*5); interval > 0 {
+		go func() {
+			for range time.Tick(interval) {
+				if err := index.Refresh(context.Background()); err != nil {
+					slog.Error("Error refreshing the location hierarchy", "error", err)
+					continue
+				}
+				i.SetupOptiiApi().Invalidate(api.Locations)
+			}
+		}()
+	}
+
+	i.locationIndex = index
+	return index
+}
@@ -0,0 +1,20 @@
+package config
+
+import "optii/operations"
+
+// SetupOperationRunner returns the runner shared by every operation. OPERATIONS_WORKERS (default 4) operations run
+// at once, OPERATIONS_QUEUE_SIZE (default 100) more may wait, each of them for at most OPERATIONS_TIMEOUT (default
+// 5m), and finished operations can be read for OPERATIONS_TTL (default 1h).
+func (i *Infra) SetupOperationRunner() operations.Runner {
+	if i.operationRunner != nil {
+		return i.operationRunner
+	}
+
+	i.operationRunner = operations.NewRunner(operations.Config{
+		Workers:   intEnv("OPERATIONS_WORKERS", operations.DefaultConfig.Workers),
+		QueueSize: intEnv("OPERATIONS_QUEUE_SIZE", operations.DefaultConfig.QueueSize),
+		Timeout:   durationEnv("OPERATIONS_TIMEOUT", operations.DefaultConfig.Timeout),
+		TTL:       durationEnv("OPERATIONS_TTL", operations.DefaultConfig.TTL),
+	})
+	return i.operationRunner
+}
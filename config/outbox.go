@@ -0,0 +1,41 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"optii/outbox"
+)
+
+// SetupOutbox returns the outbox shared by every service and starts its dispatcher. Entries are kept as files in
+// OUTBOX_DIR (default data/outbox). Pending entries are looked for every OUTBOX_POLL_INTERVAL (default 10s) and sent
+// at most OUTBOX_MAX_ATTEMPTS (default 10) times, waiting OUTBOX_RETRY_BASE_DELAY (default 30s, doubled on every
+// attempt) up to OUTBOX_RETRY_MAX_DELAY (default 30m) in between. Delivered entries are kept for OUTBOX_RETENTION
+// (default 24h).
+func (i *Infra) SetupOutbox() outbox.Outbox {
+	if i.outbox != nil {
+		return i.outbox
+	}
+
+	dir := os.Getenv("OUTBOX_DIR")
+	if dir == "" {
+		dir = "data/outbox"
+	}
+	store, err := outbox.NewFileStore(dir)
+	if err != nil {
+		panic(fmt.Errorf("opening the outbox: %w", err))
+	}
+
+	defaults := outbox.DefaultConfig
+	i.outbox = outbox.NewOutbox(i.SetupOptiiApi(), store, outbox.Config{
+		MaxAttempts:  intEnv("OUTBOX_MAX_ATTEMPTS", defaults.MaxAttempts),
+		BaseDelay:    durationEnv("OUTBOX_RETRY_BASE_DELAY", defaults.BaseDelay),
+		MaxDelay:     durationEnv("OUTBOX_RETRY_MAX_DELAY", defaults.MaxDelay),
+		PollInterval: durationEnv("OUTBOX_POLL_INTERVAL", defaults.PollInterval),
+		Retention:    durationEnv("OUTBOX_RETENTION", defaults.Retention),
+	})
+	go i.outbox.Run(context.Background())
+
+	return i.outbox
+}
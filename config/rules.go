@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"time"
+
+	"optii/rules"
+)
+
+// SetupRulesEngine loads the default job rules plus the rule files found in RULES_DIR, if set.
+// With RULES_RELOAD_INTERVAL set the rule files are re-read on that interval, so new rules apply without a redeploy.
+// The engine is shared, so every service applies the same rules.
+func (i *Infra) SetupRulesEngine() rules.Engine {
+	if i.rulesEngine != nil {
+		return i.rulesEngine
+	}
+
+	sources := []fs.FS{rules.Defaults()}
+	if dir := os.Getenv("RULES_DIR"); dir != "" {
+		sources = append(sources, os.DirFS(dir))
+	}
+
+	engine, err := rules.NewEngine(sources...)
+	if err != nil {
+		panic(fmt.Errorf("loading job rules: %w", err))
+	}
+
+	if interval := durationEnv("RULES_RELOAD_INTERVAL", 0); interval > 0 {
+		go func() {
+			for range time.Tick(interval) {
+				if err := engine.Reload(); err != nil {
+					slog.Error("Error reloading job rules", "error", err)
+				}
+			}
+		}()
+	}
+
+	i.rulesEngine = engine
+	return engine
+}
@@ -2,6 +2,38 @@ package config
 
 import "optii/services"
 
+// SetupJobService returns the job service shared by the synchronous and background job endpoints, which saves every
+// job in the outbox before sending it to Optii. Batches hold at most JOBS_BATCH_MAX_SIZE (default 100) job requests,
+// of which JOBS_BATCH_CONCURRENCY (default 4) are created at once.
 func (i *Infra) SetupJobService() services.JobService {
-	return services.NewJobService(i.SetupOptiiApi())
-}
\ No newline at end of file
+	if i.jobService != nil {
+		return i.jobService
+	}
+
+	batchLimits := services.WithBatchLimits(
+		intEnv("JOBS_BATCH_MAX_SIZE", services.DefaultBatchSize),
+		intEnv("JOBS_BATCH_CONCURRENCY", services.DefaultBatchConcurrency),
+	)
+	i.jobService = services.NewJobService(i.SetupOptiiApi(), i.SetupRulesEngine(), i.SetupJobBuilder(), i.SetupLocationIndex(), batchLimits, services.WithOutbox(i.SetupOutbox()))
+	return i.jobService
+}
+
+func (i *Infra) SetupJobBuilder() services.JobBuilder {
+	return services.NewJobBuilder()
+}
+
+func (i *Infra) SetupLocationService() services.LocationService {
+	return services.NewLocationService(i.SetupLocationIndex())
+}
+
+func (i *Infra) SetupReferenceService() services.ReferenceService {
+	return services.NewReferenceService(i.SetupOptiiApi())
+}
+
+func (i *Infra) SetupOperationService() services.OperationService {
+	return services.NewOperationService(i.SetupJobService(), i.SetupOperationRunner())
+}
+
+func (i *Infra) SetupOutboxService() services.OutboxService {
+	return services.NewOutboxService(i.SetupOutbox())
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"optii/api"
+	"optii/idempotency"
+	"optii/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	// replayedHeader marks responses replayed from the idempotency store.
+	replayedHeader          = "Idempotent-Replayed"
+	maxIdempotencyKeyLength = 255
+)
+
+// Idempotent honours the Idempotency-Key header. The first response to a key is stored and replayed for requests
+// repeated with the same key and body, while duplicates arriving before it is ready wait for it. Reusing a key for
+// a different request answers 409. Server errors are not stored, so such requests can be sent again. The key is
+// passed on to Optii as well.
+func Idempotent(store idempotency.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, utils.Response{Message: fmt.Sprintf("%s must be at most %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength)})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, utils.Response{Message: err.Error()})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		stored, err := store.Begin(c.Request.Context(), key, fingerprint(c.Request, body))
+		switch {
+		case errors.Is(err, idempotency.ErrConflict):
+			c.AbortWithStatusJSON(http.StatusConflict, utils.Response{Message: err.Error()})
+			return
+		case err != nil:
+			// The client went away while the first request was in flight.
+			c.AbortWithStatusJSON(http.StatusRequestTimeout, utils.Response{Message: err.Error()})
+			return
+		case stored != nil:
+			c.Header(replayedHeader, "true")
+			if stored.Location != "" {
+				c.Header("Location", stored.Location)
+			}
+			c.Data(stored.Status, stored.ContentType, stored.Body)
+			c.Abort()
+			return
+		}
+
+		completed := false
+		defer func() {
+			if !completed {
+				store.Release(key)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Request = c.Request.WithContext(api.WithIdempotencyKey(c.Request.Context(), key))
+
+		c.Next()
+
+		if status := recorder.Status(); status < http.StatusInternalServerError {
+			store.Complete(key, idempotency.Response{
+				Status:      status,
+				ContentType: recorder.Header().Get("Content-Type"),
+				Location:    recorder.Header().Get("Location"),
+				Body:        recorder.body.Bytes(),
+			})
+			completed = true
+		}
+	}
+}
+
+// fingerprint identifies a request by its method, URL and body.
+func fingerprint(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", req.Method, req.URL.RequestURI())
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the response body written by the handlers.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
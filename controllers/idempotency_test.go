@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"optii/api"
+	"optii/idempotency"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func idempotentRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/jobs", Idempotent(idempotency.NewMemoryStore(time.Hour)), handler)
+	return router
+}
+
+func postJob(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/jobs", bytes.NewBufferString(body))
+	if key != "" {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestIdempotentReplaysTheFirstResponse(t *testing.T) {
+	var calls atomic.Int32
+	router := idempotentRouter(func(c *gin.Context) {
+		key, _ := api.IdempotencyKey(c.Request.Context())
+		c.JSON(http.StatusCreated, gin.H{"id": calls.Add(1), "key": key})
+	})
+
+	first := postJob(router, "abc", `{"department":"Housekeeping"}`)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.JSONEq(t, `{"id":1,"key":"abc"}`, first.Body.String())
+
+	repeated := postJob(router, "abc", `{"department":"Housekeeping"}`)
+	assert.Equal(t, http.StatusCreated, repeated.Code)
+	assert.Equal(t, first.Body.String(), repeated.Body.String())
+	assert.Equal(t, "application/json; charset=utf-8", repeated.Header().Get("Content-Type"))
+	assert.Equal(t, "true", repeated.Header().Get(replayedHeader))
+
+	conflict := postJob(router, "abc", `{"department":"Engineering"}`)
+	assert.Equal(t, http.StatusConflict, conflict.Code)
+
+	other := postJob(router, "", `{"department":"Housekeeping"}`)
+	assert.JSONEq(t, `{"id":2,"key":""}`, other.Body.String())
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestIdempotentReplaysTheLocation(t *testing.T) {
+	var calls atomic.Int32
+	router := idempotentRouter(func(c *gin.Context) {
+		calls.Add(1)
+		c.Header("Location", "/operations/abc")
+		c.JSON(http.StatusAccepted, gin.H{"id": "abc"})
+	})
+
+	first := postJob(router, "abc", `{}`)
+	assert.Equal(t, "/operations/abc", first.Header().Get("Location"))
+
+	repeated := postJob(router, "abc", `{}`)
+	assert.Equal(t, http.StatusAccepted, repeated.Code)
+	assert.Equal(t, "true", repeated.Header().Get(replayedHeader))
+	assert.Equal(t, "/operations/abc", repeated.Header().Get("Location"))
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestIdempotentDoesNotStoreServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	router := idempotentRouter(func(c *gin.Context) {
+		if calls.Add(1) == 1 {
+			c.JSON(http.StatusGatewayTimeout, gin.H{"message": "timeout"})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+	})
+
+	assert.Equal(t, http.StatusGatewayTimeout, postJob(router, "abc", `{}`).Code)
+	assert.Equal(t, http.StatusCreated, postJob(router, "abc", `{}`).Code)
+	assert.Equal(t, http.StatusCreated, postJob(router, "abc", `{}`).Code)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestIdempotentDuplicatesWaitForTheFirstRequest(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	router := idempotentRouter(func(c *gin.Context) {
+		calls.Add(1)
+		<-release
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+	})
+
+	var wg sync.WaitGroup
+	codes := make([]int, 3)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = postJob(router, "abc", `{}`).Code
+		}(i)
+	}
+
+	time.Sleep(time.Millisecond * 20)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, []int{http.StatusCreated, http.StatusCreated, http.StatusCreated}, codes)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestIdempotentRejectsLongKeys(t *testing.T) {
+	router := idempotentRouter(func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	assert.Equal(t, http.StatusBadRequest, postJob(router, string(bytes.Repeat([]byte("k"), 256)), `{}`).Code)
+}
@@ -1,7 +1,13 @@
 package controllers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"optii/models"
 	"optii/services"
@@ -12,27 +18,42 @@ import (
 
 type JobController interface {
 	Create(c *gin.Context)
+	CreateBatch(c *gin.Context)
+	DryRun(c *gin.Context)
+	Get(c *gin.Context)
+	List(c *gin.Context)
+	Update(c *gin.Context)
+	Cancel(c *gin.Context)
+	AddNote(c *gin.Context)
 }
 
 type jobController struct {
-	JobService services.JobService
+	JobService       services.JobService
+	OperationService services.OperationService
 }
 
-func NewJobController(service services.JobService) JobController {
+func NewJobController(service services.JobService, operations services.OperationService) JobController {
 	return &jobController{
-		JobService: service,
+		JobService:       service,
+		OperationService: operations,
 	}
 }
 
 // Create Job godoc
 // @Summary Create an job
-// @Description create new job
+// @Description create new job, or add a note to an open duplicate job when the rule merges duplicates.
+// @Description When Optii is unavailable the job is kept in the outbox and delivered later.
 // @Tags job
 // @Accept  json
 // @Produce  json
 // @Param job body models.CreateJobRequest true "Create Job"
+// @Param Idempotency-Key header string false "repeats with the same key replay the first response"
+// @Param async query bool false "create the job in the background and answer with an operation to poll"
 // @Success 201 {object} models.CreateJobRequest
+// @Success 200 {object} models.Job
+// @Success 202 {object} models.Operation "with async, or an models.OutboxEntry when Optii is unavailable"
 // @Failure 400 {object} utils.Response
+// @Failure 409 {object} models.DuplicateJobResponse
 // @Router /job [post]
 func (ac *jobController) Create(c *gin.Context) {
 	var job models.CreateJobRequest
@@ -42,11 +63,308 @@ func (ac *jobController) Create(c *gin.Context) {
 		return
 	}
 
-	createdJob, err, httpStatus := ac.JobService.CreateJob(&job)
+	async, err := strconv.ParseBool(c.DefaultQuery("async", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Message: fmt.Sprintf("invalid async %s", c.Query("async"))})
+		return
+	}
+	if async {
+		operation, err, httpStatus := ac.OperationService.CreateJob(c.Request.Context(), &job)
+		if err == nil {
+			c.Header("Location", "/operations/"+operation.Id)
+		}
+		respond(c, operation, err, httpStatus)
+		return
+	}
+
+	createdJob, err, httpStatus := ac.JobService.CreateJob(c.Request.Context(), &job)
+	var duplicate *services.DuplicateJobError
+	if errors.As(err, &duplicate) {
+		c.JSON(httpStatus, models.DuplicateJobResponse{Message: err.Error(), JobId: duplicate.JobId})
+		return
+	}
+	var queued *services.JobQueuedError
+	if errors.As(err, &queued) {
+		c.Header("Location", "/outbox/entries/"+queued.Entry.Id)
+		c.JSON(httpStatus, queued.Entry)
+		return
+	}
 	if err != nil {
 		c.JSON(httpStatus, utils.Response{Message: err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, createdJob)
+	c.JSON(httpStatus, createdJob)
+}
+
+// CreateBatch Job godoc
+// @Summary Create many jobs
+// @Description create every job of the batch, reporting the outcome of each of them.
+// @Description Failed entries are sent again in a new batch under a new Idempotency-Key.
+// @Tags job
+// @Accept  json
+// @Produce  json
+// @Param jobs body []models.CreateJobRequest true "Create Jobs"
+// @Param Idempotency-Key header string false "repeats with the same key replay the first response"
+// @Success 200 {object} models.BatchJobResponse
+// @Failure 400 {object} utils.Response
+// @Router /jobs/batch [post]
+func (ac *jobController) CreateBatch(c *gin.Context) {
+	var entries []json.RawMessage
+	if err := c.ShouldBindJSON(&entries); err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Message: err.Error()})
+		return
+	}
+
+	jobs := make([]services.BatchJob, len(entries))
+	for i, entry := range entries {
+		var job models.CreateJobRequest
+		if err := json.Unmarshal(entry, &job); err != nil {
+			jobs[i].Err = err
+			continue
+		}
+		jobs[i].Request = &job
+	}
+
+	response, err, httpStatus := ac.JobService.CreateJobs(c.Request.Context(), jobs)
+	respond(c, response, err, httpStatus)
+}
+
+// DryRun Job godoc
+// @Summary Preview a job
+// @Description run the job rules without creating the job in Optii
+// @Tags job
+// @Accept  json
+// @Produce  json
+// @Param job body models.CreateJobRequest true "Create Job"
+// @Success 200 {object} models.JobDryRun
+// @Failure 400 {object} utils.Response
+// @Router /jobs/dry-run [post]
+func (ac *jobController) DryRun(c *gin.Context) {
+	var job models.CreateJobRequest
+
+	if err := c.ShouldBindJSON(&job); err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Message: err.Error()})
+		return
+	}
+
+	dryRun, err, httpStatus := ac.JobService.DryRun(c.Request.Context(), &job)
+	if err != nil {
+		c.JSON(httpStatus, utils.Response{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dryRun)
+}
+
+// Get Job godoc
+// @Summary Get a job
+// @Description get a job of Optii by id
+// @Tags job
+// @Produce  json
+// @Param id path int true "Job id"
+// @Success 200 {object} models.Job
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /jobs/{id} [get]
+func (ac *jobController) Get(c *gin.Context) {
+	id, ok := pathId(c)
+	if !ok {
+		return
+	}
+
+	job, err, httpStatus := ac.JobService.GetJob(c.Request.Context(), id)
+	respond(c, job, err, httpStatus)
+}
+
+// List Job godoc
+// @Summary List jobs
+// @Description list the jobs of Optii matching every given filter
+// @Tags job
+// @Produce  json
+// @Param department query string false "department id or display name"
+// @Param location query string false "location id or display name"
+// @Param status query string false "comma separated job statuses" example(new,inProgress)
+// @Param assignee query int false "assignee employee id"
+// @Param dueFrom query string false "earliest due date, RFC 3339 time or YYYY-MM-DD"
+// @Param dueTo query string false "latest due date, RFC 3339 time or YYYY-MM-DD (inclusive)"
+// @Param createdFrom query string false "earliest creation date, RFC 3339 time or YYYY-MM-DD"
+// @Param createdTo query string false "latest creation date, RFC 3339 time or YYYY-MM-DD (inclusive)"
+// @Param first query int false "page size, at most 100"
+// @Param next query int false "cursor returned as pageInfo.endCursor by the previous page"
+// @Success 200 {object} models.Jobs
+// @Failure 400 {object} utils.Response
+// @Router /jobs [get]
+func (ac *jobController) List(c *gin.Context) {
+	query, err := jobQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Message: err.Error()})
+		return
+	}
+
+	jobs, err, httpStatus := ac.JobService.ListJobs(c.Request.Context(), query)
+	respond(c, jobs, err, httpStatus)
+}
+
+// Update Job godoc
+// @Summary Update a job
+// @Description reassign, reprioritise, reschedule or change the status of a job
+// @Tags job
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Job id"
+// @Param job body models.UpdateJobRequest true "Update Job"
+// @Success 200 {object} models.Job
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /jobs/{id} [patch]
+func (ac *jobController) Update(c *gin.Context) {
+	id, ok := pathId(c)
+	if !ok {
+		return
+	}
+
+	var update models.UpdateJobRequest
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Message: err.Error()})
+		return
+	}
+
+	job, err, httpStatus := ac.JobService.UpdateJob(c.Request.Context(), id, &update)
+	respond(c, job, err, httpStatus)
+}
+
+// Cancel Job godoc
+// @Summary Cancel a job
+// @Description cancel a job that is not completed or cancelled yet
+// @Tags job
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Job id"
+// @Param job body models.CancelJobRequest false "Cancel Job"
+// @Success 200 {object} models.Job
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /jobs/{id}/cancel [post]
+func (ac *jobController) Cancel(c *gin.Context) {
+	id, ok := pathId(c)
+	if !ok {
+		return
+	}
+
+	var cancel models.CancelJobRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&cancel); err != nil {
+			c.JSON(http.StatusBadRequest, utils.Response{Message: err.Error()})
+			return
+		}
+	}
+
+	job, err, httpStatus := ac.JobService.CancelJob(c.Request.Context(), id, cancel.Reason)
+	respond(c, job, err, httpStatus)
+}
+
+// AddNote Job godoc
+// @Summary Add a note to a job
+// @Description add a note to a job
+// @Tags job
+// @Accept  json
+// @Produce  json
+// @Param id path int true "Job id"
+// @Param note body models.AddJobNoteRequest true "Add Note"
+// @Success 201 {object} models.Notes
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /jobs/{id}/notes [post]
+func (ac *jobController) AddNote(c *gin.Context) {
+	id, ok := pathId(c)
+	if !ok {
+		return
+	}
+
+	var note models.AddJobNoteRequest
+	if err := c.ShouldBindJSON(&note); err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Message: err.Error()})
+		return
+	}
+
+	created, err, httpStatus := ac.JobService.AddNote(c.Request.Context(), id, note.Note)
+	respond(c, created, err, httpStatus)
+}
+
+// jobQuery reads the job filters from the query string.
+func jobQuery(c *gin.Context) (services.JobQuery, error) {
+	var query services.JobQuery
+
+	if value := c.Query("department"); value != "" {
+		department := models.ParseReference(value)
+		query.Department = &department
+	}
+	if value := c.Query("location"); value != "" {
+		location := models.ParseReference(value)
+		query.Location = &location
+	}
+	if value := c.Query("status"); value != "" {
+		for _, name := range strings.Split(value, ",") {
+			status, err := models.ParseJobStatus(strings.TrimSpace(name))
+			if err != nil {
+				return query, err
+			}
+			query.Statuses = append(query.Statuses, status)
+		}
+	}
+
+	ints := []struct {
+		name   string
+		target *int
+	}{{"assignee", &query.Assignee}, {"first", &query.First}, {"next", &query.Next}}
+	for _, param := range ints {
+		if value := c.Query(param.name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return query, fmt.Errorf("invalid %s %s", param.name, value)
+			}
+			*param.target = n
+		}
+	}
+
+	times := []struct {
+		name     string
+		target   **time.Time
+		endOfDay bool
+	}{
+		{"dueFrom", &query.DueFrom, false},
+		{"dueTo", &query.DueTo, true},
+		{"createdFrom", &query.CreatedFrom, false},
+		{"createdTo", &query.CreatedTo, true},
+	}
+	for _, param := range times {
+		if value := c.Query(param.name); value != "" {
+			t, err := parseQueryTime(value, param.endOfDay)
+			if err != nil {
+				return query, fmt.Errorf("invalid %s %s, expected an RFC 3339 time or YYYY-MM-DD", param.name, value)
+			}
+			*param.target = &t
+		}
+	}
+
+	return query, nil
+}
+
+// parseQueryTime reads an RFC 3339 time or a date. A date stands for its start in UTC,
+// or for its end when it closes a range.
+func parseQueryTime(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		return date.Add(24*time.Hour - time.Nanosecond), nil
+	}
+	return date, nil
 }
@@ -2,12 +2,17 @@ package controllers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"optii/idempotency"
 	"optii/models"
+	"optii/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -19,28 +24,63 @@ type MockJobsService struct {
 	mock.Mock
 }
 
-func (m *MockJobsService) CreateJob(job *models.CreateJobRequest) (*models.Job, error, int) {
+func (m *MockJobsService) CreateJob(ctx context.Context, job *models.CreateJobRequest) (*models.Job, error, int) {
 	args := m.Called(job)
 	return args.Get(0).(*models.Job), args.Error(1), args.Int(2)
 }
 
+func (m *MockJobsService) CreateJobs(ctx context.Context, jobs []services.BatchJob) (*models.BatchJobResponse, error, int) {
+	args := m.Called(jobs)
+	return args.Get(0).(*models.BatchJobResponse), args.Error(1), args.Int(2)
+}
+
+func (m *MockJobsService) DryRun(ctx context.Context, job *models.CreateJobRequest) (*models.JobDryRun, error, int) {
+	args := m.Called(job)
+	return args.Get(0).(*models.JobDryRun), args.Error(1), args.Int(2)
+}
+
+func (m *MockJobsService) GetJob(ctx context.Context, id int) (*models.Job, error, int) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Job), args.Error(1), args.Int(2)
+}
+
+func (m *MockJobsService) ListJobs(ctx context.Context, query services.JobQuery) (*models.Jobs, error, int) {
+	args := m.Called(query)
+	return args.Get(0).(*models.Jobs), args.Error(1), args.Int(2)
+}
+
+func (m *MockJobsService) UpdateJob(ctx context.Context, id int, update *models.UpdateJobRequest) (*models.Job, error, int) {
+	args := m.Called(id, update)
+	return args.Get(0).(*models.Job), args.Error(1), args.Int(2)
+}
+
+func (m *MockJobsService) CancelJob(ctx context.Context, id int, reason string) (*models.Job, error, int) {
+	args := m.Called(id, reason)
+	return args.Get(0).(*models.Job), args.Error(1), args.Int(2)
+}
+
+func (m *MockJobsService) AddNote(ctx context.Context, id int, note string) (*models.Notes, error, int) {
+	args := m.Called(id, note)
+	return args.Get(0).(*models.Notes), args.Error(1), args.Int(2)
+}
+
 func TestCreateJob(t *testing.T) {
 	mockService := new(MockJobsService)
 	var desc, depart, jobItem = "test", "test", "test"
-	var location []string
-	location = append(location, "test")
+	var location []models.Reference
+	location = append(location, models.Reference{Name: "test"})
 
 	body := models.CreateJobRequest{
 		Description: &desc,
-		Department:  &depart,
-		JobItem:     &jobItem,
+		Department:  &models.Reference{Name: depart},
+		JobItem:     &models.Reference{Name: jobItem},
 		Locations:   location,
 	}
 
 	job := &models.Job{}
 	mockService.On("CreateJob", &body).Return(job, nil, http.StatusCreated)
 
-	controller := NewJobController(mockService)
+	controller := NewJobController(mockService, nil)
 
 	requestBodyBytes, err := json.Marshal(body)
 	if err != nil {
@@ -63,19 +103,19 @@ func TestCreateJob(t *testing.T) {
 func TestCreateFailDepartmentJob(t *testing.T) {
 	mockService := new(MockJobsService)
 	var desc, jobItem = "test", "test"
-	var location []string
-	location = append(location, "test")
+	var location []models.Reference
+	location = append(location, models.Reference{Name: "test"})
 
 	body := models.CreateJobRequest{
 		Description: &desc,
-		JobItem:     &jobItem,
+		JobItem:     &models.Reference{Name: jobItem},
 		Locations:   location,
 	}
 
 	job := &models.Job{}
 	mockService.On("CreateJob", &body).Return(job, nil, http.StatusBadRequest)
 
-	controller := NewJobController(mockService)
+	controller := NewJobController(mockService, nil)
 
 	requestBodyBytes, err := json.Marshal(body)
 	if err != nil {
@@ -99,19 +139,19 @@ func TestCreateFailDepartmentJob(t *testing.T) {
 func TestCreateFailItemJob(t *testing.T) {
 	mockService := new(MockJobsService)
 	var desc, depart = "test", "test"
-	var location []string
-	location = append(location, "test")
+	var location []models.Reference
+	location = append(location, models.Reference{Name: "test"})
 
 	body := models.CreateJobRequest{
 		Description: &desc,
-		Department:  &depart,
+		Department:  &models.Reference{Name: depart},
 		Locations:   location,
 	}
 
 	job := &models.Job{}
 	mockService.On("CreateJob", &body).Return(job, nil, http.StatusBadRequest)
 
-	controller := NewJobController(mockService)
+	controller := NewJobController(mockService, nil)
 
 	requestBodyBytes, err := json.Marshal(body)
 	if err != nil {
@@ -138,14 +178,14 @@ func TestCreateFailLocationJob(t *testing.T) {
 
 	body := models.CreateJobRequest{
 		Description: &desc,
-		Department:  &depart,
-		JobItem:     &jobItem,
+		Department:  &models.Reference{Name: depart},
+		JobItem:     &models.Reference{Name: jobItem},
 	}
 
 	job := &models.Job{}
 	mockService.On("CreateJob", &body).Return(job, nil, http.StatusBadRequest)
 
-	controller := NewJobController(mockService)
+	controller := NewJobController(mockService, nil)
 
 	requestBodyBytes, err := json.Marshal(body)
 	if err != nil {
@@ -164,3 +204,311 @@ func TestCreateFailLocationJob(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, recorder.Code)
 	assert.Equal(t, "{\"message\":\"locations is required\"}", recorder.Body.String())
 }
+
+func TestDryRunJob(t *testing.T) {
+	mockService := new(MockJobsService)
+	var depart, jobItem = "Room Service", "Towels"
+
+	body := models.CreateJobRequest{
+		Department: &models.Reference{Name: depart},
+		JobItem:    &models.Reference{Name: jobItem},
+		Locations:  []models.Reference{{Name: "Floor 1"}},
+	}
+
+	dryRun := &models.JobDryRun{
+		Rule:       "room-service-deliver",
+		Department: models.Department{Id: 7},
+		JobItem:    models.JobItem{Id: 3, DisplayName: "Towels"},
+		Locations:  []int{1},
+		Expansions: []models.LocationExpansion{{Location: 1, Locations: []int{101, 102}}},
+		Jobs:       []models.Job{{Action: "deliver"}},
+	}
+	mockService.On("DryRun", &body).Return(dryRun, nil, http.StatusOK)
+
+	controller := NewJobController(mockService, nil)
+
+	requestBodyBytes, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(recorder)
+	context.Request, _ = http.NewRequest("POST", "/jobs/dry-run", bytes.NewBuffer(requestBodyBytes))
+
+	controller.DryRun(context)
+
+	mockService.AssertExpectations(t)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response models.JobDryRun
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, "room-service-deliver", response.Rule)
+	assert.Equal(t, []int{101, 102}, response.Expansions[0].Locations)
+}
+
+func TestCreateJobWithIds(t *testing.T) {
+	mockService := new(MockJobsService)
+
+	expected := models.CreateJobRequest{
+		Department: &models.Reference{Id: 7},
+		JobItem:    &models.Reference{Name: "Towels"},
+		Locations:  []models.Reference{{Id: 101}, {Name: "Floor 1"}},
+	}
+	mockService.On("CreateJob", &expected).Return(&models.Job{}, nil, http.StatusCreated)
+
+	controller := NewJobController(mockService, nil)
+
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(recorder)
+	context.Request, _ = http.NewRequest("POST", "/jobs", bytes.NewBufferString(`{"department": 7, "job_item": "Towels", "locations": [101, "Floor 1"]}`))
+
+	controller.Create(context)
+
+	mockService.AssertExpectations(t)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+}
+
+func TestCreateFailInvalidReferenceJob(t *testing.T) {
+	mockService := new(MockJobsService)
+	controller := NewJobController(mockService, nil)
+
+	gin.SetMode(gin.TestMode)
+	recorder := httptest.NewRecorder()
+	context, _ := gin.CreateTestContext(recorder)
+	context.Request, _ = http.NewRequest("POST", "/jobs", bytes.NewBufferString(`{"department": 7.5, "job_item": "Towels", "locations": [101]}`))
+
+	controller.Create(context)
+
+	mockService.AssertNotCalled(t, "CreateJob", mock.Anything)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, "{\"message\":\"id 7.5 must be a positive integer\"}", recorder.Body.String())
+}
+
+func TestListJobsParsesFilters(t *testing.T) {
+	mockService := new(MockJobsService)
+
+	dueTo := time.Date(2024, 5, 1, 23, 59, 59, int(time.Second-time.Nanosecond), time.UTC)
+	createdFrom := time.Date(2024, 4, 30, 12, 0, 0, 0, time.UTC)
+	mockService.On("ListJobs", services.JobQuery{
+		Department:  &models.Reference{Id: 3},
+		Location:    &models.Reference{Name: "Room 101"},
+		Statuses:    []models.JobStatus{models.JobStatusNew, models.JobStatusOnHold},
+		Assignee:    8,
+		DueTo:       &dueTo,
+		CreatedFrom: &createdFrom,
+		First:       20,
+	}).Return(&models.Jobs{Items: []models.Job{{Id: 55}}}, nil, http.StatusOK)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/jobs", NewJobController(mockService, nil).List)
+
+	recorder := httptest.NewRecorder()
+	url := "/jobs?department=3&location=Room+101&status=new,ONHOLD&assignee=8&dueTo=2024-05-01&createdFrom=2024-04-30T12:00:00Z&first=20"
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", url, nil))
+
+	mockService.AssertExpectations(t)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestListJobsRejectsMalformedFilters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/jobs", NewJobController(new(MockJobsService), nil).List)
+
+	for _, url := range []string{"/jobs?status=done", "/jobs?assignee=bob", "/jobs?dueFrom=yesterday", "/jobs?first=ten"} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", url, nil))
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, url)
+	}
+}
+
+func TestGetJob(t *testing.T) {
+	mockService := new(MockJobsService)
+	mockService.On("GetJob", 55).Return(&models.Job{Id: 55}, nil, http.StatusOK)
+	mockService.On("GetJob", 56).Return((*models.Job)(nil), errors.New("job 56 not found"), http.StatusNotFound)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/jobs/:id", NewJobController(mockService, nil).Get)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/jobs/55", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/jobs/56", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestUpdateJob(t *testing.T) {
+	mockService := new(MockJobsService)
+	status := models.JobStatusInProgress
+	mockService.On("UpdateJob", 55, &models.UpdateJobRequest{Status: &status}).Return(&models.Job{Id: 55, Status: status}, nil, http.StatusOK)
+	mockService.On("UpdateJob", 56, mock.Anything).Return((*models.Job)(nil), errors.New("job 56 cannot move from completed to inProgress"), http.StatusConflict)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PATCH("/jobs/:id", NewJobController(mockService, nil).Update)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("PATCH", "/jobs/55", bytes.NewBufferString(`{"status":"inProgress"}`)))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"status":"inProgress"`)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("PATCH", "/jobs/56", bytes.NewBufferString(`{"status":"inProgress"}`)))
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("PATCH", "/jobs/57", bytes.NewBufferString(`{"assignee":"bob"}`)))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestCancelJobWithoutBody(t *testing.T) {
+	mockService := new(MockJobsService)
+	mockService.On("CancelJob", 55, "").Return(&models.Job{Id: 55, Status: models.JobStatusCancelled}, nil, http.StatusOK)
+	mockService.On("CancelJob", 56, "No longer needed").Return(&models.Job{Id: 56, Status: models.JobStatusCancelled}, nil, http.StatusOK)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/jobs/:id/cancel", NewJobController(mockService, nil).Cancel)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", "/jobs/55/cancel", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", "/jobs/56/cancel", bytes.NewBufferString(`{"reason":"No longer needed"}`)))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAddJobNote(t *testing.T) {
+	mockService := new(MockJobsService)
+	mockService.On("AddNote", 55, "Extra towels").Return(&models.Notes{Id: 3, Note: "Extra towels"}, nil, http.StatusCreated)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/jobs/:id/notes", NewJobController(mockService, nil).AddNote)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", "/jobs/55/notes", bytes.NewBufferString(`{"note":"Extra towels"}`)))
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", "/jobs/abc/notes", bytes.NewBufferString(`{"note":"Extra towels"}`)))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestCreateDuplicateJob(t *testing.T) {
+	mockService := new(MockJobsService)
+	mockService.On("CreateJob", mock.Anything).Return((*models.Job)(nil), &services.DuplicateJobError{JobId: 41}, http.StatusConflict)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/jobs", NewJobController(mockService, nil).Create)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", "/jobs", bytes.NewBufferString(`{"department":"Spa","job_item":"Sheets","locations":["Room 301"]}`)))
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+	assert.JSONEq(t, `{"message":"job 41 is already open for the same request","job_id":41}`, recorder.Body.String())
+}
+
+func TestCreateBatchReadsEveryEntry(t *testing.T) {
+	mockService := new(MockJobsService)
+	mockService.On("CreateJobs", mock.MatchedBy(func(jobs []services.BatchJob) bool {
+		return len(jobs) == 2 &&
+			jobs[0].Err == nil && jobs[0].Request.Department.Name == "Engineering" &&
+			jobs[1].Request == nil && jobs[1].Err.Error() == "department is required"
+	})).Return(&models.BatchJobResponse{Succeeded: 1, Failed: 1}, nil, http.StatusOK)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/jobs/batch", NewJobController(mockService, nil).CreateBatch)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", "/jobs/batch", bytes.NewBufferString(
+		`[{"department":"Engineering","job_item":"Light Bulb","locations":["Room 201"]},{"job_item":"Sheets","locations":[]}]`)))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	mockService.AssertExpectations(t)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", "/jobs/batch", bytes.NewBufferString(`{"department":"Engineering"}`)))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestCreateBatchWithFailedEntriesIsReplayed(t *testing.T) {
+	mockService := new(MockJobsService)
+	response := &models.BatchJobResponse{Succeeded: 1, Failed: 1, Results: []models.BatchJobResult{
+		{Index: 0, Status: http.StatusCreated, Outcome: models.BatchJobCreated},
+		{Index: 1, Status: http.StatusBadGateway, Outcome: models.BatchJobFailed},
+	}}
+	mockService.On("CreateJobs", mock.Anything).Return(response, nil, http.StatusOK).Once()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/jobs/batch", Idempotent(idempotency.NewMemoryStore(time.Hour)), NewJobController(mockService, nil).CreateBatch)
+
+	post := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/jobs/batch", bytes.NewBufferString(
+			`[{"department":"Engineering","job_item":"Light Bulb","locations":["Room 201"]},{"department":"Engineering","job_item":"Light Bulb","locations":["Room 202"]}]`))
+		req.Header.Set(idempotencyKeyHeader, "abc")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	first := post()
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Contains(t, first.Body.String(), `"outcome":"failed"`)
+
+	repeated := post()
+	assert.Equal(t, http.StatusOK, repeated.Code)
+	assert.Equal(t, "true", repeated.Header().Get(replayedHeader))
+	assert.Equal(t, first.Body.String(), repeated.Body.String())
+	mockService.AssertNumberOfCalls(t, "CreateJobs", 1)
+}
+
+func TestCreateJobAsync(t *testing.T) {
+	mockService := new(MockJobsService)
+	mockOperations := new(MockOperationService)
+	mockOperations.On("CreateJob", mock.Anything).Return(&models.Operation{Id: "abc", Status: models.OperationPending}, nil, http.StatusAccepted)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/jobs", NewJobController(mockService, mockOperations).Create)
+
+	body := `{"department":"Engineering","job_item":"Light Bulb","locations":["Floor 1"]}`
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", "/jobs?async=true", bytes.NewBufferString(body)))
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+	assert.Equal(t, "/operations/abc", recorder.Header().Get("Location"))
+	assert.Contains(t, recorder.Body.String(), `"status":"pending"`)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", "/jobs?async=maybe", bytes.NewBufferString(body)))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	mockService.AssertNotCalled(t, "CreateJob", mock.Anything)
+}
+
+func TestCreateQueuedJob(t *testing.T) {
+	mockService := new(MockJobsService)
+	entry := models.OutboxEntry{Id: "abc", Status: models.OutboxPending}
+	mockService.On("CreateJob", mock.Anything).Return((*models.Job)(nil), &services.JobQueuedError{Entry: entry}, http.StatusAccepted)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/jobs", NewJobController(mockService, nil).Create)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", "/jobs", bytes.NewBufferString(`{"department":"Spa","job_item":"Sheets","locations":["Room 301"]}`)))
+	assert.Equal(t, http.StatusAccepted, recorder.Code)
+	assert.Equal(t, "/outbox/entries/abc", recorder.Header().Get("Location"))
+	assert.Contains(t, recorder.Body.String(), `"status":"pending"`)
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"optii/services"
+	"optii/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LocationController interface {
+	Tree(c *gin.Context)
+	Children(c *gin.Context)
+}
+
+type locationController struct {
+	LocationService services.LocationService
+}
+
+func NewLocationController(service services.LocationService) LocationController {
+	return &locationController{
+		LocationService: service,
+	}
+}
+
+// Tree Location godoc
+// @Summary Location tree
+// @Description list the locations of the property nested by their parent location
+// @Tags location
+// @Produce  json
+// @Param type query string false "keep the locations of this type and the locations leading to them"
+// @Param depth query int false "number of levels to return, 0 for all"
+// @Success 200 {array} models.LocationNode
+// @Failure 400 {object} utils.Response
+// @Router /locations/tree [get]
+func (lc *locationController) Tree(c *gin.Context) {
+	query, err := locationQuery(c, 0)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Message: err.Error()})
+		return
+	}
+
+	tree, err, httpStatus := lc.LocationService.Tree(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(httpStatus, utils.Response{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+// Children Location godoc
+// @Summary Location children
+// @Description list the locations below a location
+// @Tags location
+// @Produce  json
+// @Param id path int true "Location id"
+// @Param type query string false "keep the locations of this type and the locations leading to them"
+// @Param depth query int false "number of levels to return, 0 for all" default(1)
+// @Success 200 {array} models.LocationNode
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /locations/{id}/children [get]
+func (lc *locationController) Children(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, utils.Response{Message: fmt.Sprintf("invalid location id %s", c.Param("id"))})
+		return
+	}
+
+	query, err := locationQuery(c, 1)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.Response{Message: err.Error()})
+		return
+	}
+
+	children, err, httpStatus := lc.LocationService.Children(c.Request.Context(), id, query)
+	if err != nil {
+		c.JSON(httpStatus, utils.Response{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, children)
+}
+
+func locationQuery(c *gin.Context, defaultDepth int) (services.LocationQuery, error) {
+	query := services.LocationQuery{Type: c.Query("type"), Depth: defaultDepth}
+	if value := c.Query("depth"); value != "" {
+		depth, err := strconv.Atoi(value)
+		if err != nil || depth < 0 {
+			return query, fmt.Errorf("invalid depth %s", value)
+		}
+		query.Depth = depth
+	}
+	return query, nil
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"optii/models"
+	"optii/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLocationService struct {
+	mock.Mock
+}
+
+func (m *MockLocationService) Tree(ctx context.Context, query services.LocationQuery) ([]models.LocationNode, error, int) {
+	args := m.Called(query)
+	return args.Get(0).([]models.LocationNode), args.Error(1), args.Int(2)
+}
+
+func (m *MockLocationService) Children(ctx context.Context, id int, query services.LocationQuery) ([]models.LocationNode, error, int) {
+	args := m.Called(id, query)
+	return args.Get(0).([]models.LocationNode), args.Error(1), args.Int(2)
+}
+
+func newLocationRouter(service services.LocationService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	controller := NewLocationController(service)
+
+	r := gin.New()
+	r.GET("/locations/tree", controller.Tree)
+	r.GET("/locations/:id/children", controller.Children)
+	return r
+}
+
+func TestLocationTree(t *testing.T) {
+	mockService := new(MockLocationService)
+	tree := []models.LocationNode{{Id: 1, Name: "Building A", Children: []models.LocationNode{{Id: 3, Name: "Floor 3"}}}}
+	mockService.On("Tree", services.LocationQuery{Type: "Floor", Depth: 2}).Return(tree, nil, http.StatusOK)
+
+	recorder := httptest.NewRecorder()
+	newLocationRouter(mockService).ServeHTTP(recorder, httptest.NewRequest("GET", "/locations/tree?type=Floor&depth=2", nil))
+
+	mockService.AssertExpectations(t)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var body []models.LocationNode
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Equal(t, tree, body)
+}
+
+func TestLocationChildren(t *testing.T) {
+	mockService := new(MockLocationService)
+	mockService.On("Children", 3, services.LocationQuery{Type: "Room", Depth: 1}).
+		Return([]models.LocationNode{{Id: 301, Name: "Room 301"}}, nil, http.StatusOK)
+
+	recorder := httptest.NewRecorder()
+	newLocationRouter(mockService).ServeHTTP(recorder, httptest.NewRequest("GET", "/locations/3/children?type=Room", nil))
+
+	mockService.AssertExpectations(t)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestLocationChildrenNotFound(t *testing.T) {
+	mockService := new(MockLocationService)
+	mockService.On("Children", 9, services.LocationQuery{Depth: 1}).
+		Return([]models.LocationNode(nil), errors.New("location 9 not found"), http.StatusNotFound)
+
+	recorder := httptest.NewRecorder()
+	newLocationRouter(mockService).ServeHTTP(recorder, httptest.NewRequest("GET", "/locations/9/children", nil))
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.JSONEq(t, `{"message": "location 9 not found"}`, recorder.Body.String())
+}
+
+func TestLocationBadRequests(t *testing.T) {
+	router := newLocationRouter(new(MockLocationService))
+
+	for _, url := range []string{"/locations/tree?depth=-1", "/locations/tree?depth=all", "/locations/abc/children", "/locations/0/children"} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", url, nil))
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, url)
+	}
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"net/http"
+
+	"optii/api"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MetricsController interface {
+	RateLimit(c *gin.Context)
+}
+
+type metricsController struct {
+	OptiiApi api.RateLimitedOptiiApi
+}
+
+func NewMetricsController(optiiApi api.RateLimitedOptiiApi) MetricsController {
+	return &metricsController{
+		OptiiApi: optiiApi,
+	}
+}
+
+// RateLimit Metrics godoc
+// @Summary Get the Optii rate limiter metrics
+// @Description how many calls to Optii waited for the shared rate limiter and for how long, in nanoseconds
+// @Tags metrics
+// @Produce  json
+// @Success 200 {object} api.RateLimitStats
+// @Router /metrics/rate-limit [get]
+func (mc *metricsController) RateLimit(c *gin.Context) {
+	c.JSON(http.StatusOK, mc.OptiiApi.RateLimitStats())
+}
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"optii/api"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubRateLimitedApi struct {
+	api.OptiiApi
+	stats api.RateLimitStats
+}
+
+func (a *stubRateLimitedApi) RateLimitStats() api.RateLimitStats {
+	return a.stats
+}
+
+func TestRateLimitMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/metrics/rate-limit", NewMetricsController(&stubRateLimitedApi{stats: api.RateLimitStats{Requests: 3, Delayed: 1, WaitTime: time.Millisecond, MaxWait: time.Millisecond}}).RateLimit)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics/rate-limit", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"requests":3,"delayed":1,"wait_time":1000000,"max_wait":1000000,"rejected":0}`, recorder.Body.String())
+}
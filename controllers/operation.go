@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"optii/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OperationController interface {
+	Get(c *gin.Context)
+}
+
+type operationController struct {
+	OperationService services.OperationService
+}
+
+func NewOperationController(service services.OperationService) OperationController {
+	return &operationController{
+		OperationService: service,
+	}
+}
+
+// Get Operation godoc
+// @Summary Get an operation
+// @Description get the status of a job request handled in the background, with the created job ids or the error
+// @Tags operation
+// @Produce  json
+// @Param id path string true "Operation id"
+// @Success 200 {object} models.Operation
+// @Failure 404 {object} utils.Response
+// @Router /operations/{id} [get]
+func (oc *operationController) Get(c *gin.Context) {
+	operation, err, httpStatus := oc.OperationService.GetOperation(c.Request.Context(), c.Param("id"))
+	respond(c, operation, err, httpStatus)
+}
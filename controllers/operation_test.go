@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"optii/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOperationService struct {
+	mock.Mock
+}
+
+func (m *MockOperationService) CreateJob(ctx context.Context, job *models.CreateJobRequest) (*models.Operation, error, int) {
+	args := m.Called(job)
+	return args.Get(0).(*models.Operation), args.Error(1), args.Int(2)
+}
+
+func (m *MockOperationService) GetOperation(ctx context.Context, id string) (*models.Operation, error, int) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Operation), args.Error(1), args.Int(2)
+}
+
+func TestGetOperation(t *testing.T) {
+	mockService := new(MockOperationService)
+	mockService.On("GetOperation", "abc").Return(&models.Operation{Id: "abc", Status: models.OperationSucceeded, JobIds: []int{41}}, nil, http.StatusOK)
+	mockService.On("GetOperation", "def").Return((*models.Operation)(nil), errors.New("operation def not found"), http.StatusNotFound)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/operations/:id", NewOperationController(mockService).Get)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/operations/abc", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"job_ids":[41]`)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/operations/def", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
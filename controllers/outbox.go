@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"optii/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OutboxController interface {
+	GetEntry(c *gin.Context)
+	DeadLetters(c *gin.Context)
+	Replay(c *gin.Context)
+}
+
+type outboxController struct {
+	OutboxService services.OutboxService
+}
+
+func NewOutboxController(service services.OutboxService) OutboxController {
+	return &outboxController{
+		OutboxService: service,
+	}
+}
+
+// GetEntry Outbox godoc
+// @Summary Get an outbox entry
+// @Description get a job saved in the outbox together with how its delivery to Optii went
+// @Tags outbox
+// @Produce  json
+// @Param id path string true "Outbox entry id"
+// @Success 200 {object} models.OutboxEntry
+// @Failure 404 {object} utils.Response
+// @Router /outbox/entries/{id} [get]
+func (oc *outboxController) GetEntry(c *gin.Context) {
+	entry, err, httpStatus := oc.OutboxService.GetEntry(c.Request.Context(), c.Param("id"))
+	respond(c, entry, err, httpStatus)
+}
+
+// DeadLetters Outbox godoc
+// @Summary List dead letters
+// @Description list the jobs Optii rejected or that ran out of delivery attempts, oldest first
+// @Tags outbox
+// @Produce  json
+// @Success 200 {array} models.OutboxEntry
+// @Router /outbox/dead-letters [get]
+func (oc *outboxController) DeadLetters(c *gin.Context) {
+	entries, err, httpStatus := oc.OutboxService.DeadLetters(c.Request.Context())
+	respond(c, entries, err, httpStatus)
+}
+
+// Replay Outbox godoc
+// @Summary Replay a dead letter
+// @Description send a dead-lettered job to Optii again, with a fresh set of delivery attempts
+// @Tags outbox
+// @Produce  json
+// @Param id path string true "Outbox entry id"
+// @Success 200 {object} models.OutboxEntry
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /outbox/dead-letters/{id}/replay [post]
+func (oc *outboxController) Replay(c *gin.Context) {
+	entry, err, httpStatus := oc.OutboxService.Replay(c.Request.Context(), c.Param("id"))
+	respond(c, entry, err, httpStatus)
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"optii/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOutboxService struct {
+	mock.Mock
+}
+
+func (m *MockOutboxService) GetEntry(ctx context.Context, id string) (*models.OutboxEntry, error, int) {
+	args := m.Called(id)
+	return args.Get(0).(*models.OutboxEntry), args.Error(1), args.Int(2)
+}
+
+func (m *MockOutboxService) DeadLetters(ctx context.Context) ([]models.OutboxEntry, error, int) {
+	args := m.Called()
+	return args.Get(0).([]models.OutboxEntry), args.Error(1), args.Int(2)
+}
+
+func (m *MockOutboxService) Replay(ctx context.Context, id string) (*models.OutboxEntry, error, int) {
+	args := m.Called(id)
+	return args.Get(0).(*models.OutboxEntry), args.Error(1), args.Int(2)
+}
+
+func TestOutboxEndpoints(t *testing.T) {
+	mockService := new(MockOutboxService)
+	mockService.On("GetEntry", "a").Return(&models.OutboxEntry{Id: "a", Status: models.OutboxPending}, nil, http.StatusOK)
+	mockService.On("DeadLetters").Return([]models.OutboxEntry{{Id: "b", Status: models.OutboxDead}}, nil, http.StatusOK)
+	mockService.On("Replay", "b").Return(&models.OutboxEntry{Id: "b", Status: models.OutboxPending}, nil, http.StatusOK)
+	mockService.On("Replay", "a").Return((*models.OutboxEntry)(nil), errors.New("outbox entry is not dead-lettered"), http.StatusConflict)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	controller := NewOutboxController(mockService)
+	router.GET("/outbox/entries/:id", controller.GetEntry)
+	router.GET("/outbox/dead-letters", controller.DeadLetters)
+	router.POST("/outbox/dead-letters/:id/replay", controller.Replay)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/outbox/entries/a", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"status":"pending"`)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("GET", "/outbox/dead-letters", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"id":"b"`)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", "/outbox/dead-letters/b/replay", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", "/outbox/dead-letters/a/replay", nil))
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+}
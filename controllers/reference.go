@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"optii/services"
+	"optii/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReferenceController interface {
+	GetDepartments(c *gin.Context)
+	GetDepartment(c *gin.Context)
+	GetJobItems(c *gin.Context)
+	GetJobItem(c *gin.Context)
+	GetLocationTypes(c *gin.Context)
+	GetLocationType(c *gin.Context)
+}
+
+type referenceController struct {
+	ReferenceService services.ReferenceService
+}
+
+func NewReferenceController(service services.ReferenceService) ReferenceController {
+	return &referenceController{
+		ReferenceService: service,
+	}
+}
+
+// GetDepartments Department godoc
+// @Summary List departments
+// @Description list the departments of Optii
+// @Tags department
+// @Produce  json
+// @Param displayName query string false "display name to search for"
+// @Param first query int false "page size"
+// @Param next query int false "cursor returned as pageInfo.endCursor by the previous page"
+// @Success 200 {object} models.Departments
+// @Failure 400 {object} utils.Response
+// @Router /departments [get]
+func (rc *referenceController) GetDepartments(c *gin.Context) {
+	query, ok := referenceQuery(c)
+	if !ok {
+		return
+	}
+	departments, err, httpStatus := rc.ReferenceService.GetDepartments(c.Request.Context(), query)
+	respond(c, departments, err, httpStatus)
+}
+
+// GetDepartment Department godoc
+// @Summary Get a department
+// @Description get a department of Optii by id
+// @Tags department
+// @Produce  json
+// @Param id path int true "Department id"
+// @Success 200 {object} models.Department
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /departments/{id} [get]
+func (rc *referenceController) GetDepartment(c *gin.Context) {
+	id, ok := pathId(c)
+	if !ok {
+		return
+	}
+	department, err, httpStatus := rc.ReferenceService.GetDepartment(c.Request.Context(), id)
+	respond(c, department, err, httpStatus)
+}
+
+// GetJobItems Job Item godoc
+// @Summary List job items
+// @Description list the job items of Optii
+// @Tags job item
+// @Produce  json
+// @Param displayName query string false "display name to search for"
+// @Param first query int false "page size"
+// @Param next query int false "cursor returned as pageInfo.endCursor by the previous page"
+// @Success 200 {object} models.JobItems
+// @Failure 400 {object} utils.Response
+// @Router /job-items [get]
+func (rc *referenceController) GetJobItems(c *gin.Context) {
+	query, ok := referenceQuery(c)
+	if !ok {
+		return
+	}
+	jobItems, err, httpStatus := rc.ReferenceService.GetJobItems(c.Request.Context(), query)
+	respond(c, jobItems, err, httpStatus)
+}
+
+// GetJobItem Job Item godoc
+// @Summary Get a job item
+// @Description get a job item of Optii by id
+// @Tags job item
+// @Produce  json
+// @Param id path int true "Job item id"
+// @Success 200 {object} models.JobItem
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /job-items/{id} [get]
+func (rc *referenceController) GetJobItem(c *gin.Context) {
+	id, ok := pathId(c)
+	if !ok {
+		return
+	}
+	jobItem, err, httpStatus := rc.ReferenceService.GetJobItem(c.Request.Context(), id)
+	respond(c, jobItem, err, httpStatus)
+}
+
+// GetLocationTypes Location Type godoc
+// @Summary List location types
+// @Description list the location types of Optii, the display name filter applies to the returned page
+// @Tags location type
+// @Produce  json
+// @Param displayName query string false "display name to search for"
+// @Param first query int false "page size"
+// @Param next query int false "cursor returned as pageInfo.endCursor by the previous page"
+// @Success 200 {object} models.LocationTypes
+// @Failure 400 {object} utils.Response
+// @Router /location-types [get]
+func (rc *referenceController) GetLocationTypes(c *gin.Context) {
+	query, ok := referenceQuery(c)
+	if !ok {
+		return
+	}
+	locationTypes, err, httpStatus := rc.ReferenceService.GetLocationTypes(c.Request.Context(), query)
+	respond(c, locationTypes, err, httpStatus)
+}
+
+// GetLocationType Location Type godoc
+// @Summary Get a location type
+// @Description get a location type of Optii by id
+// @Tags location type
+// @Produce  json
+// @Param id path int true "Location type id"
+// @Success 200 {object} models.LocationType
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /location-types/{id} [get]
+func (rc *referenceController) GetLocationType(c *gin.Context) {
+	id, ok := pathId(c)
+	if !ok {
+		return
+	}
+	locationType, err, httpStatus := rc.ReferenceService.GetLocationType(c.Request.Context(), id)
+	respond(c, locationType, err, httpStatus)
+}
+
+// referenceQuery reads the paging and display name filter, answering with a bad request when they are invalid.
+func referenceQuery(c *gin.Context) (services.ReferenceQuery, bool) {
+	query := services.ReferenceQuery{DisplayName: c.Query("displayName")}
+	params := []struct {
+		name   string
+		target *int
+	}{{"first", &query.First}, {"next", &query.Next}}
+
+	for _, param := range params {
+		name, value := param.name, c.Query(param.name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, utils.Response{Message: fmt.Sprintf("invalid %s %s", name, value)})
+			return query, false
+		}
+		*param.target = n
+	}
+	return query, true
+}
+
+// pathId reads the id path parameter, answering with a bad request when it is not a positive number.
+func pathId(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, utils.Response{Message: fmt.Sprintf("invalid id %s", c.Param("id"))})
+		return 0, false
+	}
+	return id, true
+}
+
+func respond(c *gin.Context, result interface{}, err error, httpStatus int) {
+	if err != nil {
+		c.JSON(httpStatus, utils.Response{Message: err.Error()})
+		return
+	}
+	c.JSON(httpStatus, result)
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"optii/models"
+	"optii/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReferenceService struct {
+	mock.Mock
+}
+
+func (m *MockReferenceService) GetDepartments(ctx context.Context, query services.ReferenceQuery) (*models.Departments, error, int) {
+	args := m.Called(query)
+	return args.Get(0).(*models.Departments), args.Error(1), args.Int(2)
+}
+
+func (m *MockReferenceService) GetDepartment(ctx context.Context, id int) (*models.Department, error, int) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Department), args.Error(1), args.Int(2)
+}
+
+func (m *MockReferenceService) GetJobItems(ctx context.Context, query services.ReferenceQuery) (*models.JobItems, error, int) {
+	args := m.Called(query)
+	return args.Get(0).(*models.JobItems), args.Error(1), args.Int(2)
+}
+
+func (m *MockReferenceService) GetJobItem(ctx context.Context, id int) (*models.JobItem, error, int) {
+	args := m.Called(id)
+	return args.Get(0).(*models.JobItem), args.Error(1), args.Int(2)
+}
+
+func (m *MockReferenceService) GetLocationTypes(ctx context.Context, query services.ReferenceQuery) (*models.LocationTypes, error, int) {
+	args := m.Called(query)
+	return args.Get(0).(*models.LocationTypes), args.Error(1), args.Int(2)
+}
+
+func (m *MockReferenceService) GetLocationType(ctx context.Context, id int) (*models.LocationType, error, int) {
+	args := m.Called(id)
+	return args.Get(0).(*models.LocationType), args.Error(1), args.Int(2)
+}
+
+func newReferenceRouter(service services.ReferenceService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	controller := NewReferenceController(service)
+
+	r := gin.New()
+	r.GET("/departments", controller.GetDepartments)
+	r.GET("/job-items/:id", controller.GetJobItem)
+	r.GET("/location-types", controller.GetLocationTypes)
+	return r
+}
+
+func TestGetDepartmentsPassesQueryThrough(t *testing.T) {
+	mockService := new(MockReferenceService)
+	mockService.On("GetDepartments", services.ReferenceQuery{DisplayName: "House", First: 10, Next: 30}).
+		Return(&models.Departments{Items: []models.Department{{Id: 3, Name: "Housekeeping"}}}, nil, http.StatusOK)
+
+	recorder := httptest.NewRecorder()
+	newReferenceRouter(mockService).ServeHTTP(recorder, httptest.NewRequest("GET", "/departments?displayName=House&first=10&next=30", nil))
+
+	mockService.AssertExpectations(t)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"pageInfo": {"totalCount": 0, "endCursor": 0, "hasNextPage": false}, "items": [{"id": 3, "name": "Housekeeping"}]}`, recorder.Body.String())
+}
+
+func TestGetJobItemNotFound(t *testing.T) {
+	mockService := new(MockReferenceService)
+	mockService.On("GetJobItem", 99).Return((*models.JobItem)(nil), errors.New("job item 99 not found"), http.StatusNotFound)
+
+	recorder := httptest.NewRecorder()
+	newReferenceRouter(mockService).ServeHTTP(recorder, httptest.NewRequest("GET", "/job-items/99", nil))
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.JSONEq(t, `{"message": "job item 99 not found"}`, recorder.Body.String())
+}
+
+func TestReferenceBadRequests(t *testing.T) {
+	router := newReferenceRouter(new(MockReferenceService))
+
+	for _, url := range []string{"/departments?first=ten", "/location-types?next=-1", "/job-items/abc"} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest("GET", url, nil))
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, url)
+	}
+}
@@ -15,9 +15,96 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/departments": {
+            "get": {
+                "description": "list the departments of Optii",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "department"
+                ],
+                "summary": "List departments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "display name to search for",
+                        "name": "displayName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size",
+                        "name": "first",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "cursor returned as pageInfo.endCursor by the previous page",
+                        "name": "next",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Departments"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/departments/{id}": {
+            "get": {
+                "description": "get a department of Optii by id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "department"
+                ],
+                "summary": "Get a department",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Department id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Department"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    }
+                }
+            }
+        },
         "/job": {
             "post": {
-                "description": "create new job",
+                "description": "create new job, or add a note to an open duplicate job when the rule merges duplicates.\nWhen Optii is unavailable the job is kept in the outbox and delivered later.",
                 "consumes": [
                     "application/json"
                 ],
@@ -37,15 +124,91 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.CreateJobRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "repeats with the same key replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "create the job in the background and answer with an operation to poll",
+                        "name": "async",
+                        "in": "query"
                     }
                 ],
                 "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Job"
+                        }
+                    },
                     "201": {
                         "description": "Created",
                         "schema": {
                             "$ref": "#/definitions/models.CreateJobRequest"
                         }
                     },
+                    "202": {
+                        "description": "with async, or an models.OutboxEntry when Optii is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.Operation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.DuplicateJobResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/job-items": {
+            "get": {
+                "description": "list the job items of Optii",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job item"
+                ],
+                "summary": "List job items",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "display name to search for",
+                        "name": "displayName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size",
+                        "name": "first",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "cursor returned as pageInfo.endCursor by the previous page",
+                        "name": "next",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.JobItems"
+                        }
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
@@ -54,26 +217,1421 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.CreateJobRequest": {
-            "type": "object",
-            "properties": {
-                "department": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "job_item": {
-                    "type": "string"
-                },
-                "locations": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
+        },
+        "/job-items/{id}": {
+            "get": {
+                "description": "get a job item of Optii by id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job item"
+                ],
+                "summary": "Get a job item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job item id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.JobItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs": {
+            "get": {
+                "description": "list the jobs of Optii matching every given filter",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job"
+                ],
+                "summary": "List jobs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "department id or display name",
+                        "name": "department",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "location id or display name",
+                        "name": "location",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "new,inProgress",
+                        "description": "comma separated job statuses",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "assignee employee id",
+                        "name": "assignee",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "earliest due date, RFC 3339 time or YYYY-MM-DD",
+                        "name": "dueFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "latest due date, RFC 3339 time or YYYY-MM-DD (inclusive)",
+                        "name": "dueTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "earliest creation date, RFC 3339 time or YYYY-MM-DD",
+                        "name": "createdFrom",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "latest creation date, RFC 3339 time or YYYY-MM-DD (inclusive)",
+                        "name": "createdTo",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, at most 100",
+                        "name": "first",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "cursor returned as pageInfo.endCursor by the previous page",
+                        "name": "next",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Jobs"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/batch": {
+            "post": {
+                "description": "create every job of the batch, reporting the outcome of each of them.\nFailed entries are sent again in a new batch under a new Idempotency-Key.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job"
+                ],
+                "summary": "Create many jobs",
+                "parameters": [
+                    {
+                        "description": "Create Jobs",
+                        "name": "jobs",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CreateJobRequest"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "repeats with the same key replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchJobResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/dry-run": {
+            "post": {
+                "description": "run the job rules without creating the job in Optii",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job"
+                ],
+                "summary": "Preview a job",
+                "parameters": [
+                    {
+                        "description": "Create Job",
+                        "name": "job",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateJobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.JobDryRun"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "description": "get a job of Optii by id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job"
+                ],
+                "summary": "Get a job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "reassign, reprioritise, reschedule or change the status of a job",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job"
+                ],
+                "summary": "Update a job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update Job",
+                        "name": "job",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateJobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/cancel": {
+            "post": {
+                "description": "cancel a job that is not completed or cancelled yet",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job"
+                ],
+                "summary": "Cancel a job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cancel Job",
+                        "name": "job",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.CancelJobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/notes": {
+            "post": {
+                "description": "add a note to a job",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job"
+                ],
+                "summary": "Add a note to a job",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Job id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Add Note",
+                        "name": "note",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AddJobNoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Notes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/location-types": {
+            "get": {
+                "description": "list the location types of Optii, the display name filter applies to the returned page",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "location type"
+                ],
+                "summary": "List location types",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "display name to search for",
+                        "name": "displayName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size",
+                        "name": "first",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "cursor returned as pageInfo.endCursor by the previous page",
+                        "name": "next",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LocationTypes"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/location-types/{id}": {
+            "get": {
+                "description": "get a location type of Optii by id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "location type"
+                ],
+                "summary": "Get a location type",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Location type id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LocationType"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/locations/tree": {
+            "get": {
+                "description": "list the locations of the property nested by their parent location",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "location"
+                ],
+                "summary": "Location tree",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "keep the locations of this type and the locations leading to them",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "number of levels to return, 0 for all",
+                        "name": "depth",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LocationNode"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/locations/{id}/children": {
+            "get": {
+                "description": "list the locations below a location",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "location"
+                ],
+                "summary": "Location children",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Location id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "keep the locations of this type and the locations leading to them",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "number of levels to return, 0 for all",
+                        "name": "depth",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LocationNode"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/metrics/rate-limit": {
+            "get": {
+                "description": "how many calls to Optii waited for the shared rate limiter and for how long, in nanoseconds",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "metrics"
+                ],
+                "summary": "Get the Optii rate limiter metrics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.RateLimitStats"
+                        }
+                    }
+                }
+            }
+        },
+        "/operations/{id}": {
+            "get": {
+                "description": "get the status of a job request handled in the background, with the created job ids or the error",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "operation"
+                ],
+                "summary": "Get an operation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Operation id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Operation"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/outbox/dead-letters": {
+            "get": {
+                "description": "list the jobs Optii rejected or that ran out of delivery attempts, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "outbox"
+                ],
+                "summary": "List dead letters",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.OutboxEntry"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/outbox/dead-letters/{id}/replay": {
+            "post": {
+                "description": "send a dead-lettered job to Optii again, with a fresh set of delivery attempts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "outbox"
+                ],
+                "summary": "Replay a dead letter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Outbox entry id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OutboxEntry"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/outbox/entries/{id}": {
+            "get": {
+                "description": "get a job saved in the outbox together with how its delivery to Optii went",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "outbox"
+                ],
+                "summary": "Get an outbox entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Outbox entry id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OutboxEntry"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/utils.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.RateLimitStats": {
+            "type": "object",
+            "properties": {
+                "delayed": {
+                    "description": "Delayed is the number of those requests that had to wait.",
+                    "type": "integer"
+                },
+                "max_wait": {
+                    "description": "MaxWait is the longest single wait.",
+                    "type": "integer"
+                },
+                "rejected": {
+                    "description": "Rejected is the number of requests whose deadline would pass before their turn.",
+                    "type": "integer"
+                },
+                "requests": {
+                    "description": "Requests is the number of requests that passed the limiter.",
+                    "type": "integer"
+                },
+                "wait_time": {
+                    "description": "WaitTime is the time all requests spent waiting.",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AddJobNoteRequest": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string",
+                    "example": "Guest asked for extra pillows"
+                }
+            }
+        },
+        "models.Assignee": {
+            "type": "object",
+            "properties": {
+                "autoAssign": {
+                    "type": "boolean"
+                },
+                "employeeId": {
+                    "type": "integer"
+                },
+                "firstName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastName": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BatchJobResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchJobResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BatchJobResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "job": {
+                    "$ref": "#/definitions/models.Job"
+                },
+                "job_id": {
+                    "description": "JobId is the open job a rejected duplicate request matched.",
+                    "type": "integer"
+                },
+                "outcome": {
+                    "type": "string",
+                    "example": "created"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 201
+                }
+            }
+        },
+        "models.CancelJobRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "Guest checked out"
+                }
+            }
+        },
+        "models.CreateJobRequest": {
+            "type": "object",
+            "properties": {
+                "department": {
+                    "description": "Department id or display name",
+                    "type": "string",
+                    "example": "Housekeeping"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "job_item": {
+                    "description": "Job item id or display name",
+                    "type": "string",
+                    "example": "Sheets"
+                },
+                "locations": {
+                    "description": "Location ids or display names",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Floor 1",
+                        "Room 201"
+                    ]
+                }
+            }
+        },
+        "models.Department": {
+            "type": "object",
+            "properties": {
+                "displayName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Departments": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Department"
+                    }
+                },
+                "pageInfo": {
+                    "$ref": "#/definitions/models.PageInfo"
+                }
+            }
+        },
+        "models.DryRunDuplicate": {
+            "type": "object",
+            "properties": {
+                "job_id": {
+                    "type": "integer",
+                    "example": 41
+                },
+                "policy": {
+                    "type": "string",
+                    "example": "reject"
+                }
+            }
+        },
+        "models.DuplicateJobResponse": {
+            "type": "object",
+            "properties": {
+                "job_id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Item": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Job": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "assignee": {
+                    "$ref": "#/definitions/models.Assignee"
+                },
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "department": {
+                    "$ref": "#/definitions/models.Department"
+                },
+                "displayName": {
+                    "type": "string"
+                },
+                "dueBy": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "item": {
+                    "$ref": "#/definitions/models.Item"
+                },
+                "location": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Location"
+                    }
+                },
+                "notes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Notes"
+                    }
+                },
+                "priority": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/models.Roles"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Roles"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/models.JobStatus"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.JobDryRun": {
+            "type": "object",
+            "properties": {
+                "department": {
+                    "$ref": "#/definitions/models.Department"
+                },
+                "duplicate": {
+                    "description": "Duplicate is set when the jobs would not be created because an open job already covers the request.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.DryRunDuplicate"
+                        }
+                    ]
+                },
+                "expansions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LocationExpansion"
+                    }
+                },
+                "job_item": {
+                    "$ref": "#/definitions/models.JobItem"
+                },
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Job"
+                    }
+                },
+                "locations": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.JobItem": {
+            "type": "object",
+            "properties": {
+                "displayName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.JobItems": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.JobItem"
+                    }
+                },
+                "pageInfo": {
+                    "$ref": "#/definitions/models.PageInfo"
+                }
+            }
+        },
+        "models.JobStatus": {
+            "type": "string",
+            "enum": [
+                "new",
+                "pending",
+                "inProgress",
+                "onHold",
+                "completed",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "JobStatusNew",
+                "JobStatusPending",
+                "JobStatusInProgress",
+                "JobStatusOnHold",
+                "JobStatusCompleted",
+                "JobStatusCancelled"
+            ]
+        },
+        "models.Jobs": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Job"
+                    }
+                },
+                "pageInfo": {
+                    "$ref": "#/definitions/models.PageInfo"
+                }
+            }
+        },
+        "models.Location": {
+            "type": "object",
+            "properties": {
+                "displayName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "locationType": {
+                    "$ref": "#/definitions/models.LocationType"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parentLocation": {
+                    "$ref": "#/definitions/models.LocationSimplify"
+                }
+            }
+        },
+        "models.LocationExpansion": {
+            "type": "object",
+            "properties": {
+                "location": {
+                    "type": "integer"
+                },
+                "locations": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.LocationNode": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LocationNode"
+                    }
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Floor 3"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "Floor"
+                }
+            }
+        },
+        "models.LocationSimplify": {
+            "type": "object",
+            "properties": {
+                "displayName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LocationType": {
+            "type": "object",
+            "properties": {
+                "displayName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LocationTypes": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LocationType"
+                    }
+                },
+                "pageInfo": {
+                    "$ref": "#/definitions/models.PageInfo"
+                }
+            }
+        },
+        "models.Notes": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Operation": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "http_status": {
+                    "description": "HttpStatus is the status the request would have answered if it had been handled right away.",
+                    "type": "integer",
+                    "example": 201
+                },
+                "id": {
+                    "type": "string",
+                    "example": "4f1c2b0e9a7d4e55b1c3a8f06d2e9b17"
+                },
+                "job_id": {
+                    "description": "JobId is the open job a failed operation's request matched when it was rejected as a duplicate.",
+                    "type": "integer"
+                },
+                "job_ids": {
+                    "description": "JobIds are the jobs created, or merged into, by a succeeded operation.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "outbox_entry_id": {
+                    "description": "OutboxEntryId is the outbox entry of a queued operation, which delivers the job once Optii takes it, or the\ndead-lettered entry of a failed one whose job Optii may have received.",
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.OperationStatus"
+                        }
+                    ],
+                    "example": "succeeded"
+                }
+            }
+        },
+        "models.OperationStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "succeeded",
+                "failed",
+                "queued"
+            ],
+            "x-enum-varnames": [
+                "OperationPending",
+                "OperationRunning",
+                "OperationSucceeded",
+                "OperationFailed",
+                "OperationQueued"
+            ]
+        },
+        "models.OutboxEntry": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "9b2e4c1a7f3d48e6a0c5d1b8e2f47a93"
+                },
+                "idempotency_key": {
+                    "description": "IdempotencyKey is the Idempotency-Key of the request that created the entry, if any, passed on to Optii.",
+                    "type": "string"
+                },
+                "job": {
+                    "description": "Job is the job sent to Optii.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Job"
+                        }
+                    ]
+                },
+                "job_id": {
+                    "description": "JobId is the job Optii created for a delivered entry.",
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "description": "NextAttemptAt is when a pending entry is sent again.",
+                    "type": "string"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.OutboxStatus"
+                        }
+                    ],
+                    "example": "pending"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OutboxStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "sending",
+                "delivered",
+                "dead"
+            ],
+            "x-enum-varnames": [
+                "OutboxPending",
+                "OutboxSending",
+                "OutboxDelivered",
+                "OutboxDead"
+            ]
+        },
+        "models.PageInfo": {
+            "type": "object",
+            "properties": {
+                "endCursor": {
+                    "type": "integer"
+                },
+                "hasNextPage": {
+                    "type": "boolean"
+                },
+                "totalCount": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Roles": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UpdateJobRequest": {
+            "type": "object",
+            "properties": {
+                "assignee": {
+                    "description": "Employee id of the new assignee",
+                    "type": "integer",
+                    "example": 42
+                },
+                "due_by": {
+                    "type": "string"
+                },
+                "priority": {
+                    "description": "lowest, low, medium, high or highest",
+                    "type": "string",
+                    "example": "high"
+                },
+                "status": {
+                    "description": "New status of the job, cancel jobs with POST /jobs/{id}/cancel instead",
+                    "type": "string",
+                    "example": "inProgress"
                 }
             }
         },
@@ -0,0 +1,122 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrConflict is returned when a key is used again for a different request.
+var ErrConflict = errors.New("idempotency key was already used for a different request")
+
+// Response is the response of a request, replayed when the request is repeated with the same key.
+type Response struct {
+	Status      int
+	ContentType string
+	// Location points to what an accepted request created, such as its operation or outbox entry.
+	Location string
+	Body     []byte
+}
+
+// Store remembers the responses of requests by their idempotency key.
+type Store interface {
+	// Begin claims the key for a request identified by fingerprint. It returns nil when the key was claimed, in which
+	// case the caller must call Complete or Release, and the stored response when the same request already completed.
+	// A request still in flight under the key is waited for. ErrConflict is returned when the key belongs to a
+	// different request.
+	Begin(ctx context.Context, key, fingerprint string) (*Response, error)
+	// Complete stores the response of the request holding the key.
+	Complete(key string, response Response)
+	// Release gives up the key without storing a response, so the request can be sent again.
+	Release(key string)
+}
+
+type entry struct {
+	fingerprint string
+	// done is closed once the request holding the key completed or released it.
+	done     chan struct{}
+	response *Response
+	expires  time.Time
+}
+
+type memoryStore struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	// expiry lists the completed keys in the order they expire.
+	expiry []string
+}
+
+// NewMemoryStore returns a Store keeping the responses in memory for the given time after they completed.
+func NewMemoryStore(ttl time.Duration) Store {
+	return &memoryStore{ttl: ttl, now: time.Now, entries: map[string]*entry{}}
+}
+
+func (s *memoryStore) Begin(ctx context.Context, key, fingerprint string) (*Response, error) {
+	for {
+		s.mu.Lock()
+		s.expire()
+		e, ok := s.entries[key]
+		if !ok {
+			s.entries[key] = &entry{fingerprint: fingerprint, done: make(chan struct{})}
+			s.mu.Unlock()
+			return nil, nil
+		}
+		s.mu.Unlock()
+
+		if e.fingerprint != fingerprint {
+			return nil, ErrConflict
+		}
+
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if e.response != nil {
+			return e.response, nil
+		}
+		// The first request released the key, so try to claim it again.
+	}
+}
+
+func (s *memoryStore) Complete(key string, response Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || e.response != nil {
+		return
+	}
+	e.response = &response
+	e.expires = s.now().Add(s.ttl)
+	s.expiry = append(s.expiry, key)
+	close(e.done)
+}
+
+func (s *memoryStore) Release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || e.response != nil {
+		return
+	}
+	delete(s.entries, key)
+	close(e.done)
+}
+
+// expire drops the responses older than the ttl. It must be called with mu held.
+func (s *memoryStore) expire() {
+	now := s.now()
+	for len(s.expiry) > 0 {
+		if now.Before(s.entries[s.expiry[0]].expires) {
+			return
+		}
+		delete(s.entries, s.expiry[0])
+		s.expiry = s.expiry[1:]
+	}
+}
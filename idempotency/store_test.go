@@ -0,0 +1,102 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBeginClaimsNewKeys(t *testing.T) {
+	store := NewMemoryStore(time.Hour)
+
+	stored, err := store.Begin(context.Background(), "key", "request")
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+
+	store.Complete("key", Response{Status: http.StatusCreated, Body: []byte(`{"id":1}`)})
+
+	stored, err = store.Begin(context.Background(), "key", "request")
+	assert.NoError(t, err)
+	assert.Equal(t, &Response{Status: http.StatusCreated, Body: []byte(`{"id":1}`)}, stored)
+
+	_, err = store.Begin(context.Background(), "key", "other request")
+	assert.ErrorIs(t, err, ErrConflict)
+}
+
+func TestResponsesExpire(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := &memoryStore{ttl: time.Minute, now: func() time.Time { return now }, entries: map[string]*entry{}}
+
+	store.Begin(context.Background(), "first", "request")
+	store.Complete("first", Response{Status: http.StatusCreated})
+	now = now.Add(time.Second * 30)
+	store.Begin(context.Background(), "second", "request")
+	store.Complete("second", Response{Status: http.StatusCreated})
+
+	now = now.Add(time.Second * 31)
+	stored, err := store.Begin(context.Background(), "first", "other request")
+	assert.NoError(t, err)
+	assert.Nil(t, stored, "the first key expired and can be used again")
+
+	stored, err = store.Begin(context.Background(), "second", "request")
+	assert.NoError(t, err)
+	assert.NotNil(t, stored)
+	assert.Len(t, store.entries, 2)
+}
+
+func TestDuplicatesWaitForTheFirstRequest(t *testing.T) {
+	store := NewMemoryStore(time.Hour)
+	store.Begin(context.Background(), "key", "request")
+
+	var wg sync.WaitGroup
+	responses := make([]*Response, 5)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], _ = store.Begin(context.Background(), "key", "request")
+		}(i)
+	}
+
+	time.Sleep(time.Millisecond * 20)
+	store.Complete("key", Response{Status: http.StatusCreated})
+	wg.Wait()
+
+	for _, response := range responses {
+		assert.Equal(t, http.StatusCreated, response.Status)
+	}
+}
+
+func TestReleasedKeysCanBeClaimedAgain(t *testing.T) {
+	store := NewMemoryStore(time.Hour)
+	store.Begin(context.Background(), "key", "request")
+
+	claimed := make(chan *Response)
+	go func() {
+		stored, _ := store.Begin(context.Background(), "key", "request")
+		claimed <- stored
+	}()
+
+	time.Sleep(time.Millisecond * 20)
+	store.Release("key")
+	assert.Nil(t, <-claimed, "the waiting request claims the key")
+
+	store.Complete("key", Response{Status: http.StatusCreated})
+	stored, _ := store.Begin(context.Background(), "key", "request")
+	assert.Equal(t, http.StatusCreated, stored.Status)
+}
+
+func TestWaitingStopsWithTheContext(t *testing.T) {
+	store := NewMemoryStore(time.Hour)
+	store.Begin(context.Background(), "key", "request")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+
+	_, err := store.Begin(ctx, "key", "request")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
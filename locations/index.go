@@ -0,0 +1,88 @@
+package locations
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"optii/api"
+)
+
+// Index keeps the location tree of the property in memory.
+type Index interface {
+	// Tree returns the current tree, loading it from Optii the first time.
+	Tree(ctx context.Context) (*Tree, error)
+	// Refresh loads the tree from Optii again. The current tree is kept when that fails.
+	Refresh(ctx context.Context) error
+	// LoadedAt returns when the current tree was loaded, or the zero time before the first load.
+	LoadedAt() time.Time
+}
+
+type index struct {
+	api api.OptiiApi
+	now func() time.Time
+
+	// load serialises loads, so concurrent callers of an empty index wait for a single one.
+	load     sync.Mutex
+	mu       sync.RWMutex
+	tree     *Tree
+	loadedAt time.Time
+}
+
+func NewIndex(api api.OptiiApi) Index {
+	return &index{api: api, now: time.Now}
+}
+
+func (i *index) Tree(ctx context.Context) (*Tree, error) {
+	if tree := i.current(); tree != nil {
+		return tree, nil
+	}
+
+	i.load.Lock()
+	defer i.load.Unlock()
+
+	if tree := i.current(); tree != nil {
+		return tree, nil
+	}
+	if err := i.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return i.current(), nil
+}
+
+func (i *index) Refresh(ctx context.Context) error {
+	i.load.Lock()
+	defer i.load.Unlock()
+
+	return i.refresh(ctx)
+}
+
+func (i *index) LoadedAt() time.Time {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.loadedAt
+}
+
+// refresh pages through every location and replaces the tree. It must be called with load held.
+func (i *index) refresh(ctx context.Context) error {
+	all, err := api.Collect(api.IterateLocations(ctx, i.api, nil))
+	if err != nil {
+		return err
+	}
+	tree := Build(all)
+
+	i.mu.Lock()
+	i.tree = tree
+	i.loadedAt = i.now()
+	i.mu.Unlock()
+
+	return nil
+}
+
+func (i *index) current() *Tree {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.tree
+}
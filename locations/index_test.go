@@ -0,0 +1,95 @@
+package locations
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"optii/api"
+	"optii/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// locationsApi serves the locations in pages of two.
+type locationsApi struct {
+	api.OptiiApi
+	locations []models.Location
+	calls     int32
+	err       error
+}
+
+func (a *locationsApi) GetLocations(ctx context.Context, params map[string]string) (*models.Locations, error) {
+	atomic.AddInt32(&a.calls, 1)
+	if a.err != nil {
+		return nil, a.err
+	}
+
+	start := 0
+	if params["next"] != "" {
+		start = 2
+	}
+	end := start + 2
+	if end > len(a.locations) {
+		end = len(a.locations)
+	}
+	return &models.Locations{
+		Items:    a.locations[start:end],
+		PageInfo: models.PageInfo{EndCursor: end, HasNextPage: end < len(a.locations)},
+	}, nil
+}
+
+func TestIndexLoadsOnce(t *testing.T) {
+	client := &locationsApi{locations: []models.Location{
+		location(1, "Building A", "Building", 0),
+		location(3, "Floor 3", "Floor", 1),
+		location(301, "Room 301", "Room", 3),
+	}}
+	index := NewIndex(client)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tree, err := index.Tree(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, "Building A / Floor 3 / Room 301", tree.Path(301))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&client.calls))
+	assert.False(t, index.LoadedAt().IsZero())
+}
+
+func TestIndexKeepsTreeWhenRefreshFails(t *testing.T) {
+	client := &locationsApi{locations: []models.Location{location(1, "Building A", "Building", 0)}}
+	index := NewIndex(client)
+
+	_, err := index.Tree(context.Background())
+	assert.NoError(t, err)
+
+	client.err = errors.New("unavailable")
+	assert.Error(t, index.Refresh(context.Background()))
+
+	tree, err := index.Tree(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, tree.Len())
+
+	client.err = nil
+	client.locations = append(client.locations, location(3, "Floor 3", "Floor", 1))
+	assert.NoError(t, index.Refresh(context.Background()))
+
+	tree, _ = index.Tree(context.Background())
+	assert.Equal(t, 2, tree.Len())
+}
+
+func TestIndexReportsLoadFailure(t *testing.T) {
+	index := NewIndex(&locationsApi{err: errors.New("unavailable")})
+
+	_, err := index.Tree(context.Background())
+	assert.EqualError(t, err, "unavailable")
+}
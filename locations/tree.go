@@ -0,0 +1,195 @@
+package locations
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"optii/models"
+)
+
+// PathSeparator separates the location names of a path.
+const PathSeparator = " / "
+
+// Tree is the location hierarchy of the property. It is built once and never modified,
+// so it can be queried from any goroutine.
+type Tree struct {
+	byId   map[int]*node
+	byName map[string][]*node
+	roots  []*node
+}
+
+type node struct {
+	location models.Location
+	index    int
+	parent   *node
+	children []*node
+}
+
+// Build links the locations through their ParentLocation. Locations whose parent is unknown are roots,
+// and a parent link that would close a cycle is dropped.
+func Build(locations []models.Location) *Tree {
+	t := &Tree{
+		byId:   make(map[int]*node, len(locations)),
+		byName: make(map[string][]*node),
+	}
+
+	nodes := make([]*node, 0, len(locations))
+	for _, location := range locations {
+		if _, ok := t.byId[location.Id]; ok {
+			continue
+		}
+		n := &node{location: location, index: len(nodes)}
+		t.byId[location.Id] = n
+		nodes = append(nodes, n)
+
+		key := strings.ToLower(Name(location))
+		t.byName[key] = append(t.byName[key], n)
+	}
+
+	for _, n := range nodes {
+		if n.location.ParentLocation == nil {
+			continue
+		}
+		parent, ok := t.byId[n.location.ParentLocation.Id]
+		if !ok || parent.isWithin(n) {
+			continue
+		}
+		n.parent = parent
+		parent.children = append(parent.children, n)
+	}
+
+	for _, n := range nodes {
+		if n.parent == nil {
+			t.roots = append(t.roots, n)
+		}
+	}
+
+	return t
+}
+
+// isWithin reports whether n is the given ancestor or somewhere below it.
+func (n *node) isWithin(ancestor *node) bool {
+	for current := n; current != nil; current = current.parent {
+		if current == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of locations in the tree.
+func (t *Tree) Len() int {
+	return len(t.byId)
+}
+
+// Get returns the location with the given id.
+func (t *Tree) Get(id int) (models.Location, bool) {
+	n, ok := t.byId[id]
+	if !ok {
+		return models.Location{}, false
+	}
+	return n.location, true
+}
+
+// Find returns the locations whose display name equals name, ignoring case.
+func (t *Tree) Find(name string) []models.Location {
+	return locationsOf(t.byName[strings.ToLower(name)])
+}
+
+// Roots returns the locations that are not below any other location.
+func (t *Tree) Roots() []models.Location {
+	return locationsOf(t.roots)
+}
+
+// Children returns the locations directly below the given location.
+func (t *Tree) Children(id int) []models.Location {
+	n, ok := t.byId[id]
+	if !ok {
+		return nil
+	}
+	return locationsOf(n.children)
+}
+
+// Ancestors returns the locations above the given location, starting with its parent.
+func (t *Tree) Ancestors(id int) []models.Location {
+	n, ok := t.byId[id]
+	if !ok {
+		return nil
+	}
+
+	var ancestors []models.Location
+	for parent := n.parent; parent != nil; parent = parent.parent {
+		ancestors = append(ancestors, parent.location)
+	}
+	return ancestors
+}
+
+// Descendants returns every location below the given location whose type is locationType, ignoring case.
+// An empty locationType matches every type. The locations keep the order they were built from.
+func (t *Tree) Descendants(id int, locationType string) []models.Location {
+	n, ok := t.byId[id]
+	if !ok {
+		return nil
+	}
+
+	var found []*node
+	var walk func(*node)
+	walk = func(n *node) {
+		for _, child := range n.children {
+			if locationType == "" || IsType(child.location, locationType) {
+				found = append(found, child)
+			}
+			walk(child)
+		}
+	}
+	walk(n)
+
+	sort.Slice(found, func(i, j int) bool { return found[i].index < found[j].index })
+	return locationsOf(found)
+}
+
+// Path returns the names of the location and every location above it, starting at the top,
+// for example "Building A / Floor 3 / Room 301". It is empty for unknown locations.
+func (t *Tree) Path(id int) string {
+	n, ok := t.byId[id]
+	if !ok {
+		return ""
+	}
+
+	var names []string
+	for current := n; current != nil; current = current.parent {
+		names = append(names, Name(current.location))
+	}
+	for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+		names[i], names[j] = names[j], names[i]
+	}
+	return strings.Join(names, PathSeparator)
+}
+
+// Name returns the display name of the location, falling back to its name and then its id.
+func Name(location models.Location) string {
+	if location.DisplayName != nil {
+		return *location.DisplayName
+	}
+	if location.Name != nil {
+		return *location.Name
+	}
+	return strconv.Itoa(location.Id)
+}
+
+// IsType reports whether the location is of the given type, ignoring case.
+func IsType(location models.Location, locationType string) bool {
+	return location.LocationType != nil && strings.EqualFold(location.LocationType.DisplayName, locationType)
+}
+
+func locationsOf(nodes []*node) []models.Location {
+	if len(nodes) == 0 {
+		return nil
+	}
+	locations := make([]models.Location, len(nodes))
+	for i, n := range nodes {
+		locations[i] = n.location
+	}
+	return locations
+}
@@ -0,0 +1,92 @@
+package locations
+
+import (
+	"testing"
+
+	"optii/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func location(id int, name, locationType string, parentId int) models.Location {
+	location := models.Location{Id: id, DisplayName: &name, LocationType: &models.LocationType{DisplayName: locationType}}
+	if parentId != 0 {
+		location.ParentLocation = &models.LocationSimplify{Id: parentId}
+	}
+	return location
+}
+
+func testTree() *Tree {
+	return Build([]models.Location{
+		location(301, "Room 301", "Room", 3),
+		location(1, "Building A", "Building", 0),
+		location(3, "Floor 3", "Floor", 1),
+		location(31, "East Wing", "Wing", 3),
+		location(311, "Room 311", "Room", 31),
+		location(302, "Room 302", "Room", 3),
+		location(4, "Floor 4", "Floor", 1),
+		location(99, "Storage", "Room", 1000),
+	})
+}
+
+func ids(locations []models.Location) []int {
+	result := make([]int, len(locations))
+	for i, location := range locations {
+		result[i] = location.Id
+	}
+	return result
+}
+
+func TestDescendants(t *testing.T) {
+	tree := testTree()
+
+	assert.Equal(t, []int{301, 311, 302}, ids(tree.Descendants(3, "room")))
+	assert.Equal(t, []int{301, 31, 311, 302}, ids(tree.Descendants(3, "")))
+	assert.Equal(t, []int{3, 4}, ids(tree.Descendants(1, "Floor")))
+	assert.Empty(t, tree.Descendants(4, "Room"))
+	assert.Empty(t, tree.Descendants(12345, ""))
+}
+
+func TestAncestorsAndPath(t *testing.T) {
+	tree := testTree()
+
+	assert.Equal(t, []int{31, 3, 1}, ids(tree.Ancestors(311)))
+	assert.Empty(t, tree.Ancestors(1))
+	assert.Equal(t, "Building A / Floor 3 / East Wing / Room 311", tree.Path(311))
+	assert.Equal(t, "Building A", tree.Path(1))
+	assert.Equal(t, "", tree.Path(12345))
+}
+
+func TestLookup(t *testing.T) {
+	tree := testTree()
+
+	location, ok := tree.Get(302)
+	assert.True(t, ok)
+	assert.Equal(t, "Room 302", Name(location))
+
+	_, ok = tree.Get(12345)
+	assert.False(t, ok)
+
+	assert.Equal(t, []int{3}, ids(tree.Find("floor 3")))
+	assert.Empty(t, tree.Find("Floor"))
+	assert.Equal(t, 8, tree.Len())
+}
+
+func TestUnknownParentsAreRoots(t *testing.T) {
+	tree := testTree()
+
+	assert.Equal(t, []int{1, 99}, ids(tree.Roots()))
+	assert.Equal(t, []int{3, 4}, ids(tree.Children(1)))
+}
+
+func TestCyclesAreBroken(t *testing.T) {
+	tree := Build([]models.Location{
+		location(1, "A", "Floor", 3),
+		location(2, "B", "Floor", 1),
+		location(3, "C", "Floor", 2),
+	})
+
+	assert.Equal(t, "C / A / B", tree.Path(2))
+	assert.Equal(t, []int{3}, ids(tree.Roots()))
+	assert.Equal(t, []int{1, 2}, ids(tree.Descendants(3, "")))
+}
@@ -28,13 +28,45 @@ func main() {
 
 	infra := config.NewInfra()
 	controller := infra.SetupJobController()
+	locationController := infra.SetupLocationController()
+	referenceController := infra.SetupReferenceController()
+	operationController := infra.SetupOperationController()
+	outboxController := infra.SetupOutboxController()
+	metricsController := infra.SetupMetricsController()
+	idempotent := infra.SetupIdempotency()
 
 	docs.SwaggerInfo.BasePath = "/"
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	r.GET("/metrics/rate-limit", metricsController.RateLimit)
 
 	jobs := r.Group("/jobs")
-	jobs.POST("", controller.Create)
+	jobs.POST("", idempotent, controller.Create)
+	jobs.POST("/batch", idempotent, controller.CreateBatch)
+	jobs.POST("/dry-run", controller.DryRun)
+	jobs.GET("", controller.List)
+	jobs.GET("/:id", controller.Get)
+	jobs.PATCH("/:id", controller.Update)
+	jobs.POST("/:id/cancel", controller.Cancel)
+	jobs.POST("/:id/notes", controller.AddNote)
+
+	r.GET("/operations/:id", operationController.Get)
+
+	outbox := r.Group("/outbox")
+	outbox.GET("/entries/:id", outboxController.GetEntry)
+	outbox.GET("/dead-letters", outboxController.DeadLetters)
+	outbox.POST("/dead-letters/:id/replay", outboxController.Replay)
+
+	locations := r.Group("/locations")
+	locations.GET("/tree", locationController.Tree)
+	locations.GET("/:id/children", locationController.Children)
+
+	r.GET("/departments", referenceController.GetDepartments)
+	r.GET("/departments/:id", referenceController.GetDepartment)
+	r.GET("/job-items", referenceController.GetJobItems)
+	r.GET("/job-items/:id", referenceController.GetJobItem)
+	r.GET("/location-types", referenceController.GetLocationTypes)
+	r.GET("/location-types/:id", referenceController.GetLocationType)
 
 	r.Run()
 }
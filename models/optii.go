@@ -1,14 +1,19 @@
 package models
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
 type Department struct {
-	Id   int    `json:"id"`
-	Name string `json:"name,omitempty"`
+	Id          int    `json:"id"`
+	Name        string `json:"name,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
 }
 
 type PageInfo struct {
@@ -18,7 +23,7 @@ type PageInfo struct {
 }
 
 type Location struct {
-	Id             int              `json:"id"`
+	Id             int               `json:"id"`
 	Name           *string           `json:"name,omitempty"`
 	DisplayName    *string           `json:"displayName,omitempty"`
 	ParentLocation *LocationSimplify `json:"parentLocation,omitempty"`
@@ -67,6 +72,51 @@ type Job struct {
 	Attachments []string   `json:"attachments,omitempty"`
 	Assignee    Assignee   `json:"assignee"`
 	DueBy       time.Time  `json:"dueBy"`
+	Status      JobStatus  `json:"status,omitempty"`
+	CreatedAt   *time.Time `json:"createdAt,omitempty"`
+}
+
+// JobStatus is the state of a job in Optii.
+type JobStatus string
+
+const (
+	JobStatusNew        JobStatus = "new"
+	JobStatusPending    JobStatus = "pending"
+	JobStatusInProgress JobStatus = "inProgress"
+	JobStatusOnHold     JobStatus = "onHold"
+	JobStatusCompleted  JobStatus = "completed"
+	JobStatusCancelled  JobStatus = "cancelled"
+)
+
+// JobStatuses lists every job status known to Optii.
+var JobStatuses = []JobStatus{JobStatusNew, JobStatusPending, JobStatusInProgress, JobStatusOnHold, JobStatusCompleted, JobStatusCancelled}
+
+// jobTransitions lists the statuses a job may move to from each status. Completed and cancelled jobs are final.
+var jobTransitions = map[JobStatus][]JobStatus{
+	JobStatusNew:        {JobStatusPending, JobStatusInProgress, JobStatusOnHold, JobStatusCompleted, JobStatusCancelled},
+	JobStatusPending:    {JobStatusInProgress, JobStatusOnHold, JobStatusCompleted, JobStatusCancelled},
+	JobStatusInProgress: {JobStatusPending, JobStatusOnHold, JobStatusCompleted, JobStatusCancelled},
+	JobStatusOnHold:     {JobStatusPending, JobStatusInProgress, JobStatusCancelled},
+}
+
+// CanTransitionTo reports whether a job may move from s to next.
+func (s JobStatus) CanTransitionTo(next JobStatus) bool {
+	for _, allowed := range jobTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseJobStatus returns the job status with the given name, ignoring case.
+func ParseJobStatus(name string) (JobStatus, error) {
+	for _, status := range JobStatuses {
+		if strings.EqualFold(string(status), name) {
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("unknown job status %s", name)
 }
 
 type Item struct {
@@ -92,17 +142,230 @@ type Assignee struct {
 	AutoAssign bool   `json:"autoAssign"`
 }
 
+// JobPatch holds the fields of a job to change in Optii. Fields left unset are kept.
+type JobPatch struct {
+	Priority string         `json:"priority,omitempty"`
+	Assignee *AssigneePatch `json:"assignee,omitempty"`
+	DueBy    *time.Time     `json:"dueBy,omitempty"`
+}
+
+// AssigneePatch reassigns a job, leaving the other assignee fields to Optii.
+type AssigneePatch struct {
+	EmployeeId int `json:"employeeId"`
+}
+
+// UpdateJobRequest changes a job. Fields left out are kept.
+type UpdateJobRequest struct {
+	// lowest, low, medium, high or highest
+	Priority *string `json:"priority,omitempty" example:"high"`
+	// Employee id of the new assignee
+	Assignee *int       `json:"assignee,omitempty" example:"42"`
+	DueBy    *time.Time `json:"due_by,omitempty"`
+	// New status of the job, cancel jobs with POST /jobs/{id}/cancel instead
+	Status *JobStatus `json:"status,omitempty" swaggertype:"string" example:"inProgress"`
+}
+
+type CancelJobRequest struct {
+	Reason string `json:"reason" example:"Guest checked out"`
+}
+
+type AddJobNoteRequest struct {
+	Note string `json:"note" example:"Guest asked for extra pillows"`
+}
+
+// DuplicateJobResponse is returned when a job request is rejected because an open job already covers it.
+type DuplicateJobResponse struct {
+	Message string `json:"message"`
+	JobId   int    `json:"job_id"`
+}
+
+// OperationStatus is the state of a job request handled in the background.
+type OperationStatus string
+
+const (
+	OperationPending   OperationStatus = "pending"
+	OperationRunning   OperationStatus = "running"
+	OperationSucceeded OperationStatus = "succeeded"
+	OperationFailed    OperationStatus = "failed"
+	// OperationQueued operations left their job in the outbox, to be followed through the outbox entry.
+	OperationQueued OperationStatus = "queued"
+)
+
+// Operation is a job request handled in the background.
+type Operation struct {
+	Id         string          `json:"id" example:"4f1c2b0e9a7d4e55b1c3a8f06d2e9b17"`
+	Status     OperationStatus `json:"status" example:"succeeded"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+	// JobIds are the jobs created, or merged into, by a succeeded operation.
+	JobIds []int `json:"job_ids,omitempty"`
+	// JobId is the open job a failed operation's request matched when it was rejected as a duplicate.
+	JobId int `json:"job_id,omitempty"`
+	// OutboxEntryId is the outbox entry of a queued operation, which delivers the job once Optii takes it, or the
+	// dead-lettered entry of a failed one whose job Optii may have received.
+	OutboxEntryId string `json:"outbox_entry_id,omitempty"`
+	Error         string `json:"error,omitempty"`
+	// HttpStatus is the status the request would have answered if it had been handled right away.
+	HttpStatus int `json:"http_status,omitempty" example:"201"`
+}
+
+// OutboxStatus is the delivery state of a job saved in the outbox.
+type OutboxStatus string
+
+const (
+	OutboxPending OutboxStatus = "pending"
+	// OutboxSending entries are being sent to Optii.
+	OutboxSending   OutboxStatus = "sending"
+	OutboxDelivered OutboxStatus = "delivered"
+	OutboxDead      OutboxStatus = "dead"
+)
+
+// OutboxEntry is a job saved before it is sent to Optii, together with how its delivery went.
+type OutboxEntry struct {
+	Id     string       `json:"id" example:"9b2e4c1a7f3d48e6a0c5d1b8e2f47a93"`
+	Status OutboxStatus `json:"status" example:"pending"`
+	// Job is the job sent to Optii.
+	Job Job `json:"job"`
+	// IdempotencyKey is the Idempotency-Key of the request that created the entry, if any, passed on to Optii.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	Attempts       int    `json:"attempts"`
+	// NextAttemptAt is when a pending entry is sent again.
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	// JobId is the job Optii created for a delivered entry.
+	JobId     int       `json:"job_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Outcomes of the job requests of a batch.
+const (
+	BatchJobCreated  = "created"
+	BatchJobMerged   = "merged"
+	BatchJobQueued   = "queued"
+	BatchJobRejected = "rejected"
+	BatchJobFailed   = "failed"
+)
+
+// BatchJobResult is the outcome of one job request of a batch. Rejected requests were refused by the rules or by
+// Optii, failed ones could not be sent because of an upstream error and queued ones wait in the outbox for Optii.
+type BatchJobResult struct {
+	Index   int    `json:"index"`
+	Status  int    `json:"status" example:"201"`
+	Outcome string `json:"outcome" example:"created"`
+	Job     *Job   `json:"job,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// JobId is the open job a rejected duplicate request matched.
+	JobId int `json:"job_id,omitempty"`
+}
+
+// BatchJobResponse lists the outcome of every job request of a batch in request order.
+type BatchJobResponse struct {
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BatchJobResult `json:"results"`
+}
+
+// JobDryRun describes what creating a job would do without creating it.
+type JobDryRun struct {
+	Rule       string              `json:"rule"`
+	Department Department          `json:"department"`
+	JobItem    JobItem             `json:"job_item"`
+	Locations  []int               `json:"locations"`
+	Expansions []LocationExpansion `json:"expansions,omitempty"`
+	Jobs       []Job               `json:"jobs"`
+	// Duplicate is set when the jobs would not be created because an open job already covers the request.
+	Duplicate *DryRunDuplicate `json:"duplicate,omitempty"`
+}
+
+// DryRunDuplicate is the open job a job request would be rejected for, or merged into, by the duplicate policy of
+// its rule.
+type DryRunDuplicate struct {
+	Policy string `json:"policy" example:"reject"`
+	JobId  int    `json:"job_id" example:"41"`
+}
+
+// LocationExpansion records the locations a requested location was expanded into.
+type LocationExpansion struct {
+	Location  int   `json:"location"`
+	Locations []int `json:"locations"`
+}
+
+// Reference points to an Optii entity either by its id (a JSON number) or by its display name (a JSON string).
+type Reference struct {
+	Id   int
+	Name string
+}
+
+// IsId reports whether the reference holds an id rather than a name.
+func (r Reference) IsId() bool {
+	return r.Id > 0
+}
+
+func (r Reference) String() string {
+	if r.IsId() {
+		return strconv.Itoa(r.Id)
+	}
+	return r.Name
+}
+
+// ParseReference reads a reference given as text, such as a query parameter. Positive numbers are ids, anything else a name.
+func ParseReference(value string) Reference {
+	if id, err := strconv.Atoi(value); err == nil && id > 0 {
+		return Reference{Id: id}
+	}
+	return Reference{Name: value}
+}
+
+func (r Reference) MarshalJSON() ([]byte, error) {
+	if r.IsId() {
+		return json.Marshal(r.Id)
+	}
+	return json.Marshal(r.Name)
+}
+
+func (r *Reference) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return errors.New("name must not be empty")
+		}
+		*r = Reference{Name: v}
+	case json.Number:
+		id, err := strconv.Atoi(v.String())
+		if err != nil || id <= 0 {
+			return fmt.Errorf("id %s must be a positive integer", v)
+		}
+		*r = Reference{Id: id}
+	default:
+		return errors.New("must be an id or a name")
+	}
+
+	return nil
+}
+
 type CreateJobRequest struct {
-	Description *string  `json:"description,omitempty"`
-	Department  *string  `json:"department"`
-	JobItem     *string  `json:"job_item"`
-	Locations   []string `json:"locations"`
+	Description *string `json:"description,omitempty"`
+	// Department id or display name
+	Department *Reference `json:"department" swaggertype:"string" example:"Housekeeping"`
+	// Job item id or display name
+	JobItem *Reference `json:"job_item" swaggertype:"string" example:"Sheets"`
+	// Location ids or display names
+	Locations []Reference `json:"locations" swaggertype:"array,string" example:"Floor 1,Room 201"`
 }
 
 func (r *CreateJobRequest) UnmarshalJSON(data []byte) error {
 	type Alias CreateJobRequest
 	aux := &struct {
-		Locations []string `json:"locations"`
+		Locations []Reference `json:"locations"`
 		*Alias
 	}{
 		Alias: (*Alias)(r),
@@ -132,3 +395,11 @@ func (r *CreateJobRequest) UnmarshalJSON(data []byte) error {
 
 	return nil
 }
+
+// LocationNode is a location of the property together with the locations below it.
+type LocationNode struct {
+	Id       int            `json:"id" example:"3"`
+	Name     string         `json:"name" example:"Floor 3"`
+	Type     string         `json:"type,omitempty" example:"Floor"`
+	Children []LocationNode `json:"children,omitempty"`
+}
@@ -0,0 +1,218 @@
+package operations
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"optii/models"
+)
+
+// ErrQueueFull is returned when every worker is busy and the queue holds as many operations as it can.
+var ErrQueueFull = errors.New("too many operations are waiting, try again later")
+
+// Task is the work of an operation. It returns what it did, or an error, together with the HTTP status the request
+// would have answered if it had been handled right away.
+type Task func(ctx context.Context) (Result, error, int)
+
+// Result is what a succeeded task did.
+type Result struct {
+	// JobIds are the jobs created, or merged into.
+	JobIds []int
+	// OutboxEntryId is the outbox entry holding the job. Unless the task failed, Optii could not take the job yet and
+	// the operation is queued.
+	OutboxEntryId string
+	// DuplicateJobId is the open job a request rejected as a duplicate matched.
+	DuplicateJobId int
+}
+
+// Config controls how operations are run.
+type Config struct {
+	// Workers is how many operations run at once.
+	Workers int
+	// QueueSize is how many operations may wait for a worker.
+	QueueSize int
+	// Timeout bounds how long an operation may run, zero means no limit.
+	Timeout time.Duration
+	// TTL is how long a finished operation can still be read.
+	TTL time.Duration
+}
+
+// DefaultConfig is used for the settings left at zero.
+var DefaultConfig = Config{
+	Workers:   4,
+	QueueSize: 100,
+	Timeout:   time.Minute * 5,
+	TTL:       time.Hour,
+}
+
+// Runner runs operations in the background on a pool of workers and keeps track of their status.
+type Runner interface {
+	// Submit queues the task and returns its pending operation. The task runs with the values of ctx, but is not
+	// cancelled with it, so it outlives the request that submitted it.
+	Submit(ctx context.Context, task Task) (models.Operation, error)
+	// Get returns the operation with the given id, as long as it has not expired.
+	Get(id string) (models.Operation, bool)
+}
+
+type queued struct {
+	id   string
+	ctx  context.Context
+	task Task
+}
+
+type runner struct {
+	config Config
+	now    func() time.Time
+	queue  chan queued
+
+	mu         sync.Mutex
+	operations map[string]*models.Operation
+	// expiry lists the finished operations in the order they expire.
+	expiry []string
+}
+
+// NewRunner starts the workers of a runner.
+func NewRunner(config Config) Runner {
+	if config.Workers <= 0 {
+		config.Workers = DefaultConfig.Workers
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultConfig.QueueSize
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultConfig.TTL
+	}
+
+	r := &runner{
+		config:     config,
+		now:        time.Now,
+		queue:      make(chan queued, config.QueueSize),
+		operations: map[string]*models.Operation{},
+	}
+	for i := 0; i < config.Workers; i++ {
+		go r.work()
+	}
+	return r
+}
+
+func (r *runner) Submit(ctx context.Context, task Task) (models.Operation, error) {
+	id, err := newId()
+	if err != nil {
+		return models.Operation{}, err
+	}
+
+	r.mu.Lock()
+	r.expire()
+	operation := &models.Operation{Id: id, Status: models.OperationPending, CreatedAt: r.now()}
+	r.operations[id] = operation
+	submitted := *operation
+	r.mu.Unlock()
+
+	select {
+	case r.queue <- queued{id: id, ctx: context.WithoutCancel(ctx), task: task}:
+		return submitted, nil
+	default:
+		r.mu.Lock()
+		delete(r.operations, id)
+		r.mu.Unlock()
+		return models.Operation{}, ErrQueueFull
+	}
+}
+
+func (r *runner) Get(id string) (models.Operation, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.expire()
+	operation, ok := r.operations[id]
+	if !ok {
+		return models.Operation{}, false
+	}
+	return *operation, true
+}
+
+func (r *runner) work() {
+	for next := range r.queue {
+		r.update(next.id, func(operation *models.Operation, now time.Time) {
+			operation.Status = models.OperationRunning
+			operation.StartedAt = &now
+		})
+
+		result, err, httpStatus := r.run(next)
+
+		r.update(next.id, func(operation *models.Operation, now time.Time) {
+			operation.FinishedAt = &now
+			operation.HttpStatus = httpStatus
+			operation.JobIds = result.JobIds
+			operation.OutboxEntryId = result.OutboxEntryId
+			operation.JobId = result.DuplicateJobId
+			switch {
+			case err != nil:
+				operation.Status = models.OperationFailed
+				operation.Error = err.Error()
+			case result.OutboxEntryId != "":
+				operation.Status = models.OperationQueued
+			default:
+				operation.Status = models.OperationSucceeded
+			}
+			r.expiry = append(r.expiry, next.id)
+		})
+	}
+}
+
+// run runs the task of the operation, turning a panic into a failure so the worker keeps going.
+func (r *runner) run(next queued) (result Result, err error, httpStatus int) {
+	ctx := next.ctx
+	if r.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.config.Timeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			slog.Error("Operation panicked", "id", next.id, "panic", recovered)
+			result, err, httpStatus = Result{}, fmt.Errorf("operation %s failed unexpectedly", next.id), http.StatusInternalServerError
+		}
+	}()
+
+	return next.task(ctx)
+}
+
+// update changes the operation with mu held.
+func (r *runner) update(id string, change func(operation *models.Operation, now time.Time)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if operation, ok := r.operations[id]; ok {
+		change(operation, r.now())
+	}
+}
+
+// expire drops the operations that finished more than the ttl ago. It must be called with mu held.
+func (r *runner) expire() {
+	now := r.now()
+	for len(r.expiry) > 0 {
+		operation := r.operations[r.expiry[0]]
+		if now.Before(operation.FinishedAt.Add(r.config.TTL)) {
+			return
+		}
+		delete(r.operations, r.expiry[0])
+		r.expiry = r.expiry[1:]
+	}
+}
+
+func newId() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
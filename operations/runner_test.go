@@ -0,0 +1,129 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"optii/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type contextKey struct{}
+
+func waitFor(t *testing.T, r Runner, id string, status models.OperationStatus) models.Operation {
+	var operation models.Operation
+	assert.Eventually(t, func() bool {
+		operation, _ = r.Get(id)
+		return operation.Status == status
+	}, time.Second, time.Millisecond*5)
+	return operation
+}
+
+func TestOperationsRunInTheBackground(t *testing.T) {
+	r := NewRunner(Config{Workers: 1, QueueSize: 1})
+	release := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), contextKey{}, "request"))
+	operation, err := r.Submit(ctx, func(ctx context.Context) (Result, error, int) {
+		<-release
+		if ctx.Err() != nil || ctx.Value(contextKey{}) != "request" {
+			return Result{}, errors.New("the request context leaked into the operation"), http.StatusInternalServerError
+		}
+		return Result{JobIds: []int{41}}, nil, http.StatusCreated
+	})
+	cancel()
+	assert.NoError(t, err)
+	assert.Equal(t, models.OperationPending, operation.Status)
+	assert.Len(t, operation.Id, 32)
+
+	running := waitFor(t, r, operation.Id, models.OperationRunning)
+	assert.NotNil(t, running.StartedAt)
+	assert.Nil(t, running.FinishedAt)
+
+	close(release)
+	succeeded := waitFor(t, r, operation.Id, models.OperationSucceeded)
+	assert.Equal(t, []int{41}, succeeded.JobIds)
+	assert.Equal(t, http.StatusCreated, succeeded.HttpStatus)
+	assert.Empty(t, succeeded.Error)
+	assert.NotNil(t, succeeded.FinishedAt)
+}
+
+func TestFailedOperations(t *testing.T) {
+	r := NewRunner(Config{Workers: 2, Timeout: time.Millisecond * 10})
+
+	rejected, _ := r.Submit(context.Background(), func(ctx context.Context) (Result, error, int) {
+		return Result{}, errors.New("invalid location: Room 999"), http.StatusBadRequest
+	})
+	timedOut, _ := r.Submit(context.Background(), func(ctx context.Context) (Result, error, int) {
+		<-ctx.Done()
+		return Result{}, ctx.Err(), http.StatusGatewayTimeout
+	})
+	panicked, _ := r.Submit(context.Background(), func(ctx context.Context) (Result, error, int) {
+		panic("boom")
+	})
+
+	operation := waitFor(t, r, rejected.Id, models.OperationFailed)
+	assert.Equal(t, "invalid location: Room 999", operation.Error)
+	assert.Equal(t, http.StatusBadRequest, operation.HttpStatus)
+
+	operation = waitFor(t, r, timedOut.Id, models.OperationFailed)
+	assert.Equal(t, context.DeadlineExceeded.Error(), operation.Error)
+
+	operation = waitFor(t, r, panicked.Id, models.OperationFailed)
+	assert.Equal(t, http.StatusInternalServerError, operation.HttpStatus)
+}
+
+func TestSubmitFailsWhenTheQueueIsFull(t *testing.T) {
+	r := NewRunner(Config{Workers: 1, QueueSize: 1})
+	release := make(chan struct{})
+	defer close(release)
+	block := func(ctx context.Context) (Result, error, int) {
+		<-release
+		return Result{}, nil, http.StatusCreated
+	}
+
+	first, err := r.Submit(context.Background(), block)
+	assert.NoError(t, err)
+	waitFor(t, r, first.Id, models.OperationRunning)
+
+	_, err = r.Submit(context.Background(), block)
+	assert.NoError(t, err)
+
+	_, err = r.Submit(context.Background(), block)
+	assert.ErrorIs(t, err, ErrQueueFull)
+}
+
+func TestFinishedOperationsExpire(t *testing.T) {
+	var mu sync.Mutex
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	advance := func(d time.Duration) {
+		mu.Lock()
+		now = now.Add(d)
+		mu.Unlock()
+	}
+
+	r := NewRunner(Config{Workers: 1, TTL: time.Hour}).(*runner)
+	r.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+
+	operation, _ := r.Submit(context.Background(), func(ctx context.Context) (Result, error, int) {
+		return Result{JobIds: []int{41}}, nil, http.StatusCreated
+	})
+	waitFor(t, r, operation.Id, models.OperationSucceeded)
+
+	advance(time.Minute * 59)
+	_, ok := r.Get(operation.Id)
+	assert.True(t, ok)
+
+	advance(time.Minute)
+	_, ok = r.Get(operation.Id)
+	assert.False(t, ok)
+}
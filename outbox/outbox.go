@@ -0,0 +1,326 @@
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"optii/api"
+	"optii/models"
+)
+
+var (
+	ErrNotFound      = errors.New("outbox entry not found")
+	ErrNotDeadLetter = errors.New("outbox entry is not dead-lettered")
+)
+
+// Config controls how pending entries are delivered.
+type Config struct {
+	// MaxAttempts is how many times an entry is sent before it is dead-lettered.
+	MaxAttempts int
+	// BaseDelay is the wait before the second attempt, doubled for every following one.
+	BaseDelay time.Duration
+	// MaxDelay caps the wait between two attempts.
+	MaxDelay time.Duration
+	// PollInterval is how often the dispatcher looks for entries that are due.
+	PollInterval time.Duration
+	// Retention is how long delivered entries are kept.
+	Retention time.Duration
+}
+
+// DefaultConfig is used for the settings left at zero.
+var DefaultConfig = Config{
+	MaxAttempts:  10,
+	BaseDelay:    time.Second * 30,
+	MaxDelay:     time.Minute * 30,
+	PollInterval: time.Second * 10,
+	Retention:    time.Hour * 24,
+}
+
+// Outbox saves jobs before they are sent to Optii and keeps sending the ones Optii could not take until they are
+// delivered, or dead-lettered when they run out of attempts. Since Optii is not known to recognise repeated job
+// creations, only jobs that certainly never reached Optii are sent again; the others are dead-lettered for someone to
+// check whether Optii created them before replaying them.
+type Outbox interface {
+	// Deliver saves the job and sends it to Optii right away. When Optii cannot be reached, the job is not returned
+	// and the pending entry is left for the dispatcher, or dead-lettered when Optii may have received it. When Optii
+	// rejects the job, the entry is dropped and the error returned.
+	Deliver(ctx context.Context, job *models.Job) (*models.Job, *models.OutboxEntry, error)
+	// Dispatch sends every pending entry that is due once.
+	Dispatch(ctx context.Context)
+	// Run dispatches on every poll interval until ctx is done.
+	Run(ctx context.Context)
+	Get(id string) (models.OutboxEntry, bool)
+	DeadLetters() []models.OutboxEntry
+	// Replay moves a dead-lettered entry back to pending, to be sent again with a fresh set of attempts.
+	Replay(id string) (models.OutboxEntry, error)
+}
+
+type outbox struct {
+	api    api.OptiiApi
+	store  Store
+	config Config
+	now    func() time.Time
+
+	mu sync.Mutex
+	// sending holds the entries being sent, so an entry is never sent twice at once.
+	sending map[string]bool
+}
+
+func NewOutbox(api api.OptiiApi, store Store, config Config) Outbox {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultConfig.MaxAttempts
+	}
+	if config.BaseDelay <= 0 {
+		config.BaseDelay = DefaultConfig.BaseDelay
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = DefaultConfig.MaxDelay
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultConfig.PollInterval
+	}
+	if config.Retention <= 0 {
+		config.Retention = DefaultConfig.Retention
+	}
+	o := &outbox{api: api, store: store, config: config, now: time.Now, sending: map[string]bool{}}
+	o.recover()
+	return o
+}
+
+func (o *outbox) Deliver(ctx context.Context, job *models.Job) (*models.Job, *models.OutboxEntry, error) {
+	id, err := newId()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := o.now()
+	entry := models.OutboxEntry{
+		Id:        id,
+		Status:    models.OutboxPending,
+		Job:       *job,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	entry.IdempotencyKey, _ = api.IdempotencyKey(ctx)
+
+	o.claim(id)
+	defer o.release(id)
+
+	if err := o.store.Save(entry); err != nil {
+		return nil, nil, fmt.Errorf("saving job to the outbox: %w", err)
+	}
+
+	created, err := o.send(ctx, &entry)
+	if err != nil && rejected(err) {
+		// The client is told right away, so there is nothing left to deliver.
+		if deleteErr := o.store.Delete(id); deleteErr != nil {
+			slog.Error("Error deleting rejected outbox entry", "id", id, "error", deleteErr)
+		}
+		return nil, nil, err
+	}
+	if saveErr := o.store.Save(entry); saveErr != nil {
+		slog.Error("Error saving outbox entry", "id", id, "error", saveErr)
+	}
+	if err != nil {
+		slog.Warn("Optii is unavailable, job left in the outbox", "id", id, "status", entry.Status, "error", err)
+		return nil, &entry, nil
+	}
+	return created, &entry, nil
+}
+
+func (o *outbox) Dispatch(ctx context.Context) {
+	now := o.now()
+	for _, entry := range o.store.List(models.OutboxPending) {
+		if ctx.Err() != nil {
+			return
+		}
+		if entry.NextAttemptAt != nil && entry.NextAttemptAt.After(now) {
+			continue
+		}
+		if !o.claim(entry.Id) {
+			continue
+		}
+
+		// The entry may have changed since it was listed.
+		if current, ok := o.store.Get(entry.Id); ok && current.Status == models.OutboxPending {
+			if _, err := o.send(ctx, &current); err != nil {
+				slog.Warn("Error delivering outbox entry", "id", current.Id, "attempts", current.Attempts, "status", current.Status, "error", err)
+			}
+			if err := o.store.Save(current); err != nil {
+				slog.Error("Error saving outbox entry", "id", current.Id, "error", err)
+			}
+		}
+		o.release(entry.Id)
+	}
+
+	for _, entry := range o.store.List(models.OutboxDelivered) {
+		if now.Sub(entry.UpdatedAt) >= o.config.Retention {
+			if err := o.store.Delete(entry.Id); err != nil {
+				slog.Error("Error deleting delivered outbox entry", "id", entry.Id, "error", err)
+			}
+		}
+	}
+}
+
+func (o *outbox) Run(ctx context.Context) {
+	ticker := time.NewTicker(o.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		o.Dispatch(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (o *outbox) Get(id string) (models.OutboxEntry, bool) {
+	return o.store.Get(id)
+}
+
+func (o *outbox) DeadLetters() []models.OutboxEntry {
+	return o.store.List(models.OutboxDead)
+}
+
+func (o *outbox) Replay(id string) (models.OutboxEntry, error) {
+	if !o.claim(id) {
+		return models.OutboxEntry{}, ErrNotDeadLetter
+	}
+	defer o.release(id)
+
+	entry, ok := o.store.Get(id)
+	if !ok {
+		return models.OutboxEntry{}, ErrNotFound
+	}
+	if entry.Status != models.OutboxDead {
+		return models.OutboxEntry{}, ErrNotDeadLetter
+	}
+
+	now := o.now()
+	entry.Status = models.OutboxPending
+	entry.Attempts = 0
+	entry.NextAttemptAt = &now
+	entry.UpdatedAt = now
+	if err := o.store.Save(entry); err != nil {
+		return models.OutboxEntry{}, err
+	}
+	return entry, nil
+}
+
+// recover dead-letters the entries a previous run was sending when it stopped, since Optii may have received them.
+func (o *outbox) recover() {
+	for _, entry := range o.store.List(models.OutboxSending) {
+		entry.Status = models.OutboxDead
+		entry.LastError = "interrupted while sending, Optii may have received the job"
+		entry.NextAttemptAt = nil
+		entry.UpdatedAt = o.now()
+		if err := o.store.Save(entry); err != nil {
+			slog.Error("Error saving interrupted outbox entry", "id", entry.Id, "error", err)
+		}
+	}
+}
+
+// send makes one attempt at delivering the entry and records its outcome in the entry. The entry is saved as
+// sending first, so it is not sent again should the service stop before the outcome is saved.
+func (o *outbox) send(ctx context.Context, entry *models.OutboxEntry) (*models.Job, error) {
+	entry.Status = models.OutboxSending
+	entry.UpdatedAt = o.now()
+	if err := o.store.Save(*entry); err != nil {
+		// Nothing was sent, so the entry stays pending.
+		entry.Status = models.OutboxPending
+		return nil, fmt.Errorf("saving outbox entry: %w", err)
+	}
+
+	entry.Attempts++
+	if entry.IdempotencyKey != "" {
+		ctx = api.WithIdempotencyKey(ctx, entry.IdempotencyKey)
+	}
+	created, err := o.api.CreateJob(ctx, &entry.Job)
+
+	now := o.now()
+	entry.UpdatedAt = now
+	entry.NextAttemptAt = nil
+	switch {
+	case err == nil:
+		entry.Status = models.OutboxDelivered
+		entry.JobId = created.Id
+		entry.LastError = ""
+	case !resendable(err) || entry.Attempts >= o.config.MaxAttempts:
+		entry.Status = models.OutboxDead
+		entry.LastError = err.Error()
+	default:
+		entry.Status = models.OutboxPending
+		entry.LastError = err.Error()
+		next := now.Add(o.delay(entry.Attempts))
+		entry.NextAttemptAt = &next
+	}
+	return created, err
+}
+
+// delay returns the wait after the given attempt.
+func (o *outbox) delay(attempt int) time.Duration {
+	delay := o.config.BaseDelay
+	for i := 1; i < attempt && delay < o.config.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, o.config.MaxDelay)
+}
+
+// claim marks the entry as being sent. It returns false when it already is.
+func (o *outbox) claim(id string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.sending[id] {
+		return false
+	}
+	o.sending[id] = true
+	return true
+}
+
+func (o *outbox) release(id string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.sending, id)
+}
+
+// resendable reports whether the job can be sent again without Optii creating it twice: the request never reached
+// Optii, or Optii turned it away before processing it because of our credentials or its load.
+func resendable(err error) bool {
+	if api.NotSent(err) {
+		return true
+	}
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	}
+	return false
+}
+
+// rejected reports whether Optii refused the job itself, so sending it again cannot succeed.
+func rejected(err error) bool {
+	var apiErr *api.Error
+	return errors.As(err, &apiErr) && apiErr.IsClientError() && !resendable(err)
+}
+
+func newId() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,253 @@
+package outbox
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"optii/api"
+	"optii/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// jobsApi answers job creations with the queued errors, creating the job once they run out.
+type jobsApi struct {
+	api.OptiiApi
+
+	mu   sync.Mutex
+	errs []error
+	keys []string
+}
+
+func (a *jobsApi) CreateJob(ctx context.Context, job *models.Job) (*models.Job, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key, _ := api.IdempotencyKey(ctx)
+	a.keys = append(a.keys, key)
+	if len(a.errs) > 0 {
+		err := a.errs[0]
+		a.errs = a.errs[1:]
+		return nil, err
+	}
+	return &models.Job{Id: 41, Action: job.Action}, nil
+}
+
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestOutbox(t *testing.T, client api.OptiiApi) (*outbox, *clock) {
+	store, err := NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+
+	c := &clock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	o := NewOutbox(client, store, Config{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Minute * 90}).(*outbox)
+	o.now = c.Now
+	return o, c
+}
+
+var unavailable = &api.Error{StatusCode: http.StatusServiceUnavailable}
+
+func TestDeliverSendsRightAway(t *testing.T) {
+	client := &jobsApi{}
+	o, _ := newTestOutbox(t, client)
+
+	created, entry, err := o.Deliver(context.Background(), &models.Job{Action: "clean"})
+	assert.NoError(t, err)
+	assert.Equal(t, 41, created.Id)
+	assert.Equal(t, models.OutboxDelivered, entry.Status)
+	assert.Equal(t, []string{""}, client.keys, "no idempotency key is made up")
+
+	stored, _ := o.Get(entry.Id)
+	assert.Equal(t, 41, stored.JobId)
+}
+
+func TestDeliverKeepsJobsOptiiCannotTake(t *testing.T) {
+	client := &jobsApi{errs: []error{unavailable, unavailable}}
+	o, c := newTestOutbox(t, client)
+
+	ctx := api.WithIdempotencyKey(context.Background(), "request-key")
+	created, entry, err := o.Deliver(ctx, &models.Job{Action: "clean"})
+	assert.NoError(t, err)
+	assert.Nil(t, created)
+	assert.Equal(t, models.OutboxPending, entry.Status)
+	assert.Equal(t, 1, entry.Attempts)
+	assert.Equal(t, c.Now().Add(time.Minute), *entry.NextAttemptAt)
+
+	o.Dispatch(context.Background())
+	assert.Len(t, client.keys, 1, "the entry is not due yet")
+
+	c.Advance(time.Minute)
+	o.Dispatch(context.Background())
+	stored, _ := o.Get(entry.Id)
+	assert.Equal(t, models.OutboxPending, stored.Status)
+	assert.Equal(t, c.Now().Add(time.Minute*2), *stored.NextAttemptAt, "the delay doubles")
+
+	c.Advance(time.Minute * 2)
+	o.Dispatch(context.Background())
+	stored, _ = o.Get(entry.Id)
+	assert.Equal(t, models.OutboxDelivered, stored.Status)
+	assert.Equal(t, 41, stored.JobId)
+	assert.Equal(t, 3, stored.Attempts)
+	assert.Equal(t, []string{"request-key", "request-key", "request-key"}, client.keys)
+
+	c.Advance(DefaultConfig.Retention)
+	o.Dispatch(context.Background())
+	_, ok := o.Get(entry.Id)
+	assert.False(t, ok, "delivered entries are dropped after the retention")
+}
+
+func TestDeliverKeepsJobsThatNeverReachedOptii(t *testing.T) {
+	refused := &url.Error{Op: "Post", URL: "http://optii", Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}
+	client := &jobsApi{errs: []error{refused}}
+	o, c := newTestOutbox(t, client)
+
+	_, entry, err := o.Deliver(context.Background(), &models.Job{Action: "clean"})
+	assert.NoError(t, err)
+	assert.Equal(t, models.OutboxPending, entry.Status)
+
+	c.Advance(time.Minute)
+	o.Dispatch(context.Background())
+	stored, _ := o.Get(entry.Id)
+	assert.Equal(t, models.OutboxDelivered, stored.Status)
+}
+
+func TestDeliverDeadLettersJobsOptiiMayHaveReceived(t *testing.T) {
+	for _, err := range []error{
+		&api.Error{StatusCode: http.StatusBadGateway},
+		&api.Error{StatusCode: http.StatusInternalServerError},
+		context.DeadlineExceeded,
+	} {
+		client := &jobsApi{errs: []error{err}}
+		o, c := newTestOutbox(t, client)
+
+		created, entry, deliverErr := o.Deliver(context.Background(), &models.Job{Action: "clean"})
+		assert.NoError(t, deliverErr)
+		assert.Nil(t, created)
+		assert.Equal(t, models.OutboxDead, entry.Status, err.Error())
+		assert.Equal(t, 1, entry.Attempts)
+
+		c.Advance(time.Hour)
+		o.Dispatch(context.Background())
+		assert.Len(t, client.keys, 1, "the job is not sent again")
+		assert.Len(t, o.DeadLetters(), 1)
+	}
+}
+
+// recordingStore records the statuses entries are saved with.
+type recordingStore struct {
+	Store
+	statuses []models.OutboxStatus
+}
+
+func (s *recordingStore) Save(entry models.OutboxEntry) error {
+	s.statuses = append(s.statuses, entry.Status)
+	return s.Store.Save(entry)
+}
+
+func TestEntriesAreSavedAsSendingBeforeTheyAreSent(t *testing.T) {
+	dir := t.TempDir()
+	files, err := NewFileStore(dir)
+	assert.NoError(t, err)
+	store := &recordingStore{Store: files}
+
+	o := NewOutbox(&jobsApi{}, store, Config{})
+	_, entry, err := o.Deliver(context.Background(), &models.Job{Action: "clean"})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.OutboxStatus{models.OutboxPending, models.OutboxSending, models.OutboxDelivered}, store.statuses)
+
+	// A service stopped while sending leaves the entry sending.
+	entry.Status = models.OutboxSending
+	entry.Id = "interrupted"
+	assert.NoError(t, files.Save(*entry))
+
+	reopened, err := NewFileStore(dir)
+	assert.NoError(t, err)
+	client := &jobsApi{}
+	o = NewOutbox(client, reopened, Config{})
+	o.Dispatch(context.Background())
+
+	assert.Empty(t, client.keys, "the interrupted entry is not sent again")
+	dead := o.DeadLetters()
+	if assert.Len(t, dead, 1) {
+		assert.Equal(t, "interrupted", dead[0].Id)
+		assert.Equal(t, "interrupted while sending, Optii may have received the job", dead[0].LastError)
+	}
+}
+
+func TestDeliverDropsJobsOptiiRejects(t *testing.T) {
+	client := &jobsApi{errs: []error{&api.Error{StatusCode: http.StatusUnprocessableEntity}}}
+	o, _ := newTestOutbox(t, client)
+
+	_, entry, err := o.Deliver(context.Background(), &models.Job{Action: "clean"})
+	assert.Error(t, err)
+	assert.Nil(t, entry)
+	assert.Empty(t, o.store.List(models.OutboxPending))
+	assert.Empty(t, o.DeadLetters())
+}
+
+func TestEntriesAreDeadLetteredAndReplayed(t *testing.T) {
+	client := &jobsApi{errs: []error{unavailable, unavailable, unavailable}}
+	o, c := newTestOutbox(t, client)
+
+	_, entry, _ := o.Deliver(context.Background(), &models.Job{Action: "clean"})
+	for i := 0; i < 2; i++ {
+		c.Advance(time.Hour)
+		o.Dispatch(context.Background())
+	}
+
+	dead := o.DeadLetters()
+	assert.Len(t, dead, 1)
+	assert.Equal(t, entry.Id, dead[0].Id)
+	assert.Equal(t, 3, dead[0].Attempts)
+	assert.Contains(t, dead[0].LastError, "503")
+
+	_, err := o.Replay("unknown")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	replayed, err := o.Replay(entry.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, models.OutboxPending, replayed.Status)
+	assert.Equal(t, 0, replayed.Attempts)
+
+	_, err = o.Replay(entry.Id)
+	assert.ErrorIs(t, err, ErrNotDeadLetter)
+
+	o.Dispatch(context.Background())
+	stored, _ := o.Get(entry.Id)
+	assert.Equal(t, models.OutboxDelivered, stored.Status)
+	assert.Empty(t, o.DeadLetters())
+}
+
+func TestRejectedEntriesAreDeadLettered(t *testing.T) {
+	client := &jobsApi{errs: []error{unavailable, &api.Error{StatusCode: http.StatusBadRequest}}}
+	o, c := newTestOutbox(t, client)
+
+	o.Deliver(context.Background(), &models.Job{Action: "clean"})
+	c.Advance(time.Minute)
+	o.Dispatch(context.Background())
+
+	dead := o.DeadLetters()
+	assert.Len(t, dead, 1)
+	assert.Equal(t, 2, dead[0].Attempts)
+}
@@ -0,0 +1,133 @@
+package outbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"optii/models"
+)
+
+// Store keeps the outbox entries.
+type Store interface {
+	// Save writes the entry, replacing the entry with the same id.
+	Save(entry models.OutboxEntry) error
+	Get(id string) (models.OutboxEntry, bool)
+	// List returns the entries with the given status, oldest first.
+	List(status models.OutboxStatus) []models.OutboxEntry
+	Delete(id string) error
+}
+
+type fileStore struct {
+	dir string
+
+	mu      sync.Mutex
+	entries map[string]models.OutboxEntry
+}
+
+// NewFileStore keeps every entry as a JSON file in dir, which is created when missing.
+// The entries already in dir are loaded, so pending entries survive a restart.
+func NewFileStore(dir string) (Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &fileStore{dir: dir, entries: make(map[string]models.OutboxEntry, len(files))}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var entry models.OutboxEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		s.entries[entry.Id] = entry
+	}
+	return s, nil
+}
+
+// Save writes the entry to a temporary file first and renames it, so a crash never leaves a partial entry behind.
+func (s *fileStore) Save(entry models.OutboxEntry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp, err := os.CreateTemp(s.dir, entry.Id+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path(entry.Id)); err != nil {
+		return err
+	}
+
+	s.entries[entry.Id] = entry
+	return nil
+}
+
+func (s *fileStore) Get(id string) (models.OutboxEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[id]
+	return entry, ok
+}
+
+func (s *fileStore) List(status models.OutboxStatus) []models.OutboxEntry {
+	s.mu.Lock()
+	var entries []models.OutboxEntry
+	for _, entry := range s.entries {
+		if entry.Status == status {
+			entries = append(entries, entry)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].Id < entries[j].Id
+		}
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries
+}
+
+func (s *fileStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	delete(s.entries, id)
+	return nil
+}
+
+func (s *fileStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
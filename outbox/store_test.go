@@ -0,0 +1,68 @@
+package outbox
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"optii/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileStoreKeepsEntriesAcrossRestarts(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	store, err := NewFileStore(dir)
+	assert.NoError(t, err)
+
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	assert.NoError(t, store.Save(models.OutboxEntry{Id: "b", Status: models.OutboxPending, CreatedAt: created.Add(time.Minute)}))
+	assert.NoError(t, store.Save(models.OutboxEntry{Id: "a", Status: models.OutboxPending, CreatedAt: created, Job: models.Job{Action: "clean"}}))
+	assert.NoError(t, store.Save(models.OutboxEntry{Id: "c", Status: models.OutboxDead, CreatedAt: created}))
+	assert.NoError(t, store.Save(models.OutboxEntry{Id: "b", Status: models.OutboxDelivered, CreatedAt: created.Add(time.Minute), JobId: 41}))
+	assert.NoError(t, store.Delete("c"))
+
+	files, _ := os.ReadDir(dir)
+	assert.Len(t, files, 2, "no temporary files are left behind")
+
+	reopened, err := NewFileStore(dir)
+	assert.NoError(t, err)
+
+	pending := reopened.List(models.OutboxPending)
+	assert.Len(t, pending, 1)
+	assert.Equal(t, "clean", pending[0].Job.Action)
+	assert.True(t, created.Equal(pending[0].CreatedAt))
+
+	delivered, ok := reopened.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 41, delivered.JobId)
+
+	_, ok = reopened.Get("c")
+	assert.False(t, ok)
+	assert.Empty(t, reopened.List(models.OutboxDead))
+}
+
+func TestFileStoreListsOldestFirst(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, id := range []string{"c", "a", "b"} {
+		assert.NoError(t, store.Save(models.OutboxEntry{Id: id, Status: models.OutboxDead, CreatedAt: created.Add(time.Duration(i) * time.Minute)}))
+	}
+
+	var ids []string
+	for _, entry := range store.List(models.OutboxDead) {
+		ids = append(ids, entry.Id)
+	}
+	assert.Equal(t, []string{"c", "a", "b"}, ids)
+}
+
+func TestFileStoreRejectsCorruptEntries(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte("{"), 0o644))
+
+	_, err := NewFileStore(dir)
+	assert.ErrorContains(t, err, "a.json")
+}
@@ -0,0 +1,188 @@
+package rules
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed defaults/*.yaml
+var defaults embed.FS
+
+// Defaults returns the rule files shipped with the service.
+func Defaults() fs.FS {
+	sub, err := fs.Sub(defaults, "defaults")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// Input is the resolved job request a rule is matched against.
+type Input struct {
+	Department string
+	JobItem    string
+	Locations  []Location
+}
+
+// Location is a requested location together with its location type.
+// Ancestors holds the names of the locations above it and is only needed by rules using Within.
+type Location struct {
+	Name      string
+	Type      string
+	Ancestors []string
+}
+
+// MatchError is returned when no rule accepts the input.
+// Rule is set when a rule matched the department and job item but rejected the locations.
+type MatchError struct {
+	Rule   *Rule
+	Reason string
+}
+
+func (e *MatchError) Error() string {
+	if e.Rule == nil {
+		return e.Reason
+	}
+	if e.Rule.Error == "" {
+		return fmt.Sprintf("rule %s: %s", e.Rule.Name, e.Reason)
+	}
+	return fmt.Sprintf("%s: %s", e.Rule.Error, e.Reason)
+}
+
+type Engine interface {
+	Match(input Input) (*Rule, error)
+	Rules() []Rule
+	Reload() error
+}
+
+type engine struct {
+	sources []fs.FS
+	mu      sync.RWMutex
+	rules   []Rule
+}
+
+// NewEngine loads every rule file (.yaml, .yml or .json) from the given sources.
+// A rule in a later source replaces an earlier rule with the same name.
+func NewEngine(sources ...fs.FS) (Engine, error) {
+	e := &engine{sources: sources}
+	if err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Reload reads the rule files again, keeping the current rules if any of them is invalid.
+func (e *engine) Reload() error {
+	var rules []Rule
+	index := make(map[string]int)
+
+	for _, source := range e.sources {
+		loaded, err := Load(source)
+		if err != nil {
+			return err
+		}
+		for _, rule := range loaded {
+			if i, ok := index[rule.Name]; ok {
+				rules[i] = rule
+				continue
+			}
+			index[rule.Name] = len(rules)
+			rules = append(rules, rule)
+		}
+	}
+
+	e.mu.Lock()
+	e.rules = rules
+	e.mu.Unlock()
+
+	return nil
+}
+
+func (e *engine) Rules() []Rule {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	rules := make([]Rule, len(e.rules))
+	copy(rules, e.rules)
+	return rules
+}
+
+// Match returns the first rule accepting the input.
+// When no rule does, the error explains why the closest rule rejected it.
+func (e *engine) Match(input Input) (*Rule, error) {
+	var rejected *MatchError
+	for _, rule := range e.Rules() {
+		rule := rule
+		if !rule.matchesRequest(input.Department, input.JobItem) {
+			continue
+		}
+		reason := rule.checkLocations(input.Locations)
+		if reason == "" {
+			return &rule, nil
+		}
+		if rejected == nil {
+			rejected = &MatchError{Rule: &rule, Reason: reason}
+		}
+	}
+
+	if rejected != nil {
+		return nil, rejected
+	}
+	return nil, &MatchError{Reason: fmt.Sprintf("no job rule matches department %s and job item %s", input.Department, input.JobItem)}
+}
+
+// Load parses every rule file at the root of fsys in file name order.
+func Load(fsys fs.FS) ([]Rule, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var rules []Rule
+	names := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		var unmarshal func([]byte, interface{}) error
+		switch strings.ToLower(path.Ext(entry.Name())) {
+		case ".yaml", ".yml":
+			unmarshal = yaml.Unmarshal
+		case ".json":
+			unmarshal = json.Unmarshal
+		default:
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		var rule Rule
+		if err := unmarshal(data, &rule); err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		if other, ok := names[rule.Name]; ok {
+			return nil, fmt.Errorf("%s: rule %s is already declared in %s", entry.Name(), rule.Name, other)
+		}
+		names[rule.Name] = entry.Name()
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,139 @@
+package rules
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultRules(t *testing.T) {
+	engine, err := NewEngine(Defaults())
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		input  Input
+		action string
+		err    string
+	}{
+		{
+			name:   "housekeeping floor",
+			input:  Input{Department: "Housekeeping", JobItem: "sheets", Locations: []Location{{Name: "Floor 1", Type: "Floor"}}},
+			action: "clean",
+		},
+		{
+			name:  "housekeeping other location type",
+			input: Input{Department: "Housekeeping", JobItem: "Blanket", Locations: []Location{{Name: "Lobby", Type: "Public Area"}}},
+			err:   "Housekeeping jobs need Room or Floor locations: location Lobby is of type Public Area",
+		},
+		{
+			name:  "housekeeping other job item",
+			input: Input{Department: "Housekeeping", JobItem: "Towels", Locations: []Location{{Name: "Room 101", Type: "Room"}}},
+			err:   "no job rule matches department Housekeeping and job item Towels",
+		},
+		{
+			name:   "engineering any location",
+			input:  Input{Department: "Engineering", JobItem: "Light Bulb", Locations: []Location{{Name: "Lobby", Type: "Public Area"}}},
+			action: "repair",
+		},
+		{
+			name:  "room service without locations",
+			input: Input{Department: "Room Service", JobItem: "Towels"},
+			err:   "Room Service jobs need at least one location: at least 1 location(s) required, got 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := engine.Match(tt.input)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+
+				var matchErr *MatchError
+				assert.True(t, errors.As(err, &matchErr))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.action, rule.Action)
+		})
+	}
+}
+
+func TestNewEngineOverridesRulesByName(t *testing.T) {
+	custom := fstest.MapFS{
+		"engineering.json": {Data: []byte(`{"name": "engineering-repair", "department": "Engineering", "action": "inspect", "priority": "high"}`)},
+		"spa.yml":          {Data: []byte("name: spa-restock\ndepartment: Spa\naction: restock\n")},
+		"README.md":        {Data: []byte("ignored")},
+	}
+
+	engine, err := NewEngine(Defaults(), custom)
+	assert.NoError(t, err)
+	assert.Len(t, engine.Rules(), 4)
+
+	rule, err := engine.Match(Input{Department: "Engineering", JobItem: "Light Bulb"})
+	assert.NoError(t, err)
+	assert.Equal(t, "inspect", rule.Action)
+	assert.Equal(t, ExpansionNone, rule.Expansion.Strategy)
+
+	rule, err = engine.Match(Input{Department: "spa", JobItem: "Robe"})
+	assert.NoError(t, err)
+	assert.Equal(t, "spa-restock", rule.Name)
+}
+
+func TestLoadRejectsInvalidRules(t *testing.T) {
+	tests := map[string]string{
+		"missing action":     "name: a\ndepartment: Spa\n",
+		"unknown expansion":  "name: a\ndepartment: Spa\naction: clean\nexpansion:\n  strategy: siblings\n",
+		"expansion source":   "name: a\ndepartment: Spa\naction: clean\nexpansion:\n  strategy: descendants\n",
+		"location count":     "name: a\ndepartment: Spa\naction: clean\nlocations:\n  min: 2\n  max: 1\n",
+		"malformed document": "name: [a\n",
+		"unknown duplicates": "name: a\ndepartment: Spa\naction: clean\nduplicates:\n  policy: ignore\n",
+		"duplicate window":   "name: a\ndepartment: Spa\naction: clean\nduplicates:\n  policy: reject\n  window: soon\n",
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(fstest.MapFS{"rule.yaml": {Data: []byte(data)}})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestWithinMatchesAncestors(t *testing.T) {
+	custom := fstest.MapFS{
+		"spa.yaml": {Data: []byte("name: spa-restock\ndepartment: Spa\naction: restock\nlocations:\n  within: [Building A, Building B]\n")},
+	}
+	engine, err := NewEngine(custom)
+	assert.NoError(t, err)
+	assert.True(t, engine.Rules()[0].NeedsAncestors())
+
+	rule, err := engine.Match(Input{Department: "Spa", Locations: []Location{{Name: "Room 301", Ancestors: []string{"Floor 3", "building b"}}}})
+	assert.NoError(t, err)
+	assert.Equal(t, "spa-restock", rule.Name)
+
+	_, err = engine.Match(Input{Department: "Spa", Locations: []Location{
+		{Name: "Room 301", Ancestors: []string{"Floor 3", "Building A"}},
+		{Name: "Annex", Ancestors: []string{"Building C"}},
+	}})
+	assert.EqualError(t, err, "rule spa-restock: location Annex is not within Building A or Building B")
+}
+
+func TestDuplicatePolicies(t *testing.T) {
+	custom := fstest.MapFS{
+		"a.yaml": {Data: []byte("name: a\ndepartment: Spa\naction: clean\nduplicates:\n  policy: merge\n  window: 90m\n")},
+		"b.json": {Data: []byte(`{"name": "b", "department": "Spa", "action": "clean", "duplicates": {"policy": "reject", "window": "2h"}}`)},
+		"c.yaml": {Data: []byte("name: c\ndepartment: Spa\naction: clean\nduplicates:\n  policy: reject\n")},
+		"d.yaml": {Data: []byte("name: d\ndepartment: Spa\naction: clean\n")},
+	}
+
+	loaded, err := Load(custom)
+	assert.NoError(t, err)
+	assert.Equal(t, Duplicates{Policy: DuplicatesMerge, Window: Duration(time.Minute * 90)}, loaded[0].Duplicates)
+	assert.Equal(t, Duplicates{Policy: DuplicatesReject, Window: Duration(time.Hour * 2)}, loaded[1].Duplicates)
+	assert.Equal(t, Duplicates{Policy: DuplicatesReject, Window: Duration(DefaultDuplicateWindow)}, loaded[2].Duplicates)
+	assert.Equal(t, Duplicates{Policy: DuplicatesCreate}, loaded[3].Duplicates)
+}
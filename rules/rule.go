@@ -0,0 +1,180 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	ExpansionNone        = "none"
+	ExpansionDescendants = "descendants"
+)
+
+const (
+	DuplicatesCreate = "create"
+	DuplicatesReject = "reject"
+	DuplicatesMerge  = "merge"
+)
+
+// DefaultDuplicateWindow is the duplicate window of rules that reject or merge duplicates without setting one.
+const DefaultDuplicateWindow = time.Hour
+
+// Rule describes which job requests it applies to and how the resulting job is built.
+type Rule struct {
+	Name       string        `json:"name" yaml:"name"`
+	Department string        `json:"department" yaml:"department"`
+	JobItems   []string      `json:"job_items,omitempty" yaml:"job_items"`
+	Locations  LocationMatch `json:"locations" yaml:"locations"`
+	Action     string        `json:"action" yaml:"action"`
+	Priority   string        `json:"priority,omitempty" yaml:"priority"`
+	Expansion  Expansion     `json:"expansion" yaml:"expansion"`
+	Duplicates Duplicates    `json:"duplicates" yaml:"duplicates"`
+	Error      string        `json:"error,omitempty" yaml:"error"`
+}
+
+// LocationMatch restricts the locations a rule accepts. Empty Types accepts every location type and a zero Max means no limit.
+// With Within set, every location must be below one of the named locations, for example a building.
+type LocationMatch struct {
+	Types  []string `json:"types,omitempty" yaml:"types"`
+	Min    int      `json:"min,omitempty" yaml:"min"`
+	Max    int      `json:"max,omitempty" yaml:"max"`
+	Within []string `json:"within,omitempty" yaml:"within"`
+}
+
+// Expansion describes how the given locations are turned into the locations of the job.
+// With the descendants strategy every location of type From is replaced by the locations of type Type below it
+// (every location below it when Type is empty). SingleLocation only expands requests with exactly one location.
+type Expansion struct {
+	Strategy       string `json:"strategy,omitempty" yaml:"strategy"`
+	From           string `json:"from,omitempty" yaml:"from"`
+	Type           string `json:"type,omitempty" yaml:"type"`
+	SingleLocation bool   `json:"single_location,omitempty" yaml:"single_location"`
+}
+
+// Duplicates decides what happens to a job when an open job with the same department, job item, action and locations
+// was created less than Window ago: with the create policy the job is created anyway, with reject it is refused and
+// with merge a note is added to the open job instead.
+type Duplicates struct {
+	Policy string   `json:"policy,omitempty" yaml:"policy"`
+	Window Duration `json:"window,omitempty" yaml:"window"`
+}
+
+// Duration is a time.Duration written like 90m or 2h in rule files.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (r *Rule) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("rule name is required")
+	}
+	if r.Department == "" {
+		return fmt.Errorf("rule %s: department is required", r.Name)
+	}
+	if r.Action == "" {
+		return fmt.Errorf("rule %s: action is required", r.Name)
+	}
+	if r.Locations.Min < 0 || r.Locations.Max < 0 || (r.Locations.Max > 0 && r.Locations.Max < r.Locations.Min) {
+		return fmt.Errorf("rule %s: invalid location count %d..%d", r.Name, r.Locations.Min, r.Locations.Max)
+	}
+
+	switch r.Expansion.Strategy {
+	case "", ExpansionNone:
+		r.Expansion.Strategy = ExpansionNone
+	case ExpansionDescendants:
+		if r.Expansion.From == "" {
+			return fmt.Errorf("rule %s: expansion from is required for the %s strategy", r.Name, ExpansionDescendants)
+		}
+	default:
+		return fmt.Errorf("rule %s: unknown expansion strategy %s", r.Name, r.Expansion.Strategy)
+	}
+
+	switch r.Duplicates.Policy {
+	case "", DuplicatesCreate:
+		r.Duplicates.Policy = DuplicatesCreate
+	case DuplicatesReject, DuplicatesMerge:
+		if r.Duplicates.Window < 0 {
+			return fmt.Errorf("rule %s: invalid duplicate window %s", r.Name, time.Duration(r.Duplicates.Window))
+		}
+		if r.Duplicates.Window == 0 {
+			r.Duplicates.Window = Duration(DefaultDuplicateWindow)
+		}
+	default:
+		return fmt.Errorf("rule %s: unknown duplicate policy %s", r.Name, r.Duplicates.Policy)
+	}
+
+	return nil
+}
+
+func (r *Rule) matchesRequest(department, jobItem string) bool {
+	if !strings.EqualFold(r.Department, department) {
+		return false
+	}
+	if len(r.JobItems) == 0 {
+		return true
+	}
+	for _, item := range r.JobItems {
+		if strings.EqualFold(item, jobItem) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkLocations returns the reason the given locations are not accepted by the rule, or an empty string.
+func (r *Rule) checkLocations(locations []Location) string {
+	count := len(locations)
+	if count < r.Locations.Min {
+		return fmt.Sprintf("at least %d location(s) required, got %d", r.Locations.Min, count)
+	}
+	if r.Locations.Max > 0 && count > r.Locations.Max {
+		return fmt.Sprintf("at most %d location(s) allowed, got %d", r.Locations.Max, count)
+	}
+
+	for _, location := range locations {
+		if len(r.Locations.Types) > 0 && !r.acceptsType(location.Type) {
+			return fmt.Sprintf("location %s is of type %s", location.Name, location.Type)
+		}
+		if len(r.Locations.Within) > 0 && !r.acceptsAncestors(location.Ancestors) {
+			return fmt.Sprintf("location %s is not within %s", location.Name, strings.Join(r.Locations.Within, " or "))
+		}
+	}
+	return ""
+}
+
+// NeedsAncestors reports whether the rule looks at the locations above the requested ones.
+func (r *Rule) NeedsAncestors() bool {
+	return len(r.Locations.Within) > 0
+}
+
+func (r *Rule) acceptsAncestors(ancestors []string) bool {
+	for _, ancestor := range ancestors {
+		for _, within := range r.Locations.Within {
+			if strings.EqualFold(within, ancestor) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *Rule) acceptsType(locationType string) bool {
+	for _, t := range r.Locations.Types {
+		if strings.EqualFold(t, locationType) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,203 +1,299 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
-	"strings"
 	"time"
 
 	"optii/api"
+	"optii/locations"
 	"optii/models"
+	"optii/outbox"
+	"optii/rules"
 )
 
 type JobService interface {
-	CreateJob(job *models.CreateJobRequest) (*models.Job, error, int)
+	CreateJob(ctx context.Context, job *models.CreateJobRequest) (*models.Job, error, int)
+	CreateJobs(ctx context.Context, jobs []BatchJob) (*models.BatchJobResponse, error, int)
+	DryRun(ctx context.Context, job *models.CreateJobRequest) (*models.JobDryRun, error, int)
+	GetJob(ctx context.Context, id int) (*models.Job, error, int)
+	ListJobs(ctx context.Context, query JobQuery) (*models.Jobs, error, int)
+	UpdateJob(ctx context.Context, id int, update *models.UpdateJobRequest) (*models.Job, error, int)
+	CancelJob(ctx context.Context, id int, reason string) (*models.Job, error, int)
+	AddNote(ctx context.Context, id int, note string) (*models.Notes, error, int)
 }
 
 type jobService struct {
-	api api.OptiiApi
+	api       api.OptiiApi
+	rules     rules.Engine
+	builder   JobBuilder
+	hierarchy locations.Index
+	now       func() time.Time
+
+	batchSize        int
+	batchConcurrency int
+	// lookups is only set while creating a batch of jobs.
+	lookups *lookupMemo
+	// outbox, when set, saves every job before it is sent to Optii.
+	outbox outbox.Outbox
 }
 
-func NewJobService(api api.OptiiApi) JobService {
-	return &jobService{
-		api: api,
+func NewJobService(api api.OptiiApi, rules rules.Engine, builder JobBuilder, hierarchy locations.Index, options ...JobServiceOption) JobService {
+	s := &jobService{
+		api:              api,
+		rules:            rules,
+		builder:          builder,
+		hierarchy:        hierarchy,
+		now:              time.Now,
+		batchSize:        DefaultBatchSize,
+		batchConcurrency: DefaultBatchConcurrency,
+	}
+	for _, option := range options {
+		option(s)
 	}
+	return s
 }
 
-func (s *jobService) departmentExists(department string) (bool, error) {
-	dep, err := s.api.GetDepartments(department, 0, 0)
-	if err != nil {
-		return false, err
+// expandLocations applies the expansion of the matched rule to the resolved locations.
+// Locations of the expanded type are replaced by the matching locations below them, duplicates are dropped.
+// The returned expansions record which locations each expanded location was replaced by.
+func (s *jobService) expandLocations(ctx context.Context, rule *rules.Rule, requested []models.Location) ([]models.Location, []models.LocationExpansion, error, int) {
+	expansion := rule.Expansion
+	if expansion.Strategy != rules.ExpansionDescendants || (expansion.SingleLocation && len(requested) != 1) {
+		return requested, nil, nil, http.StatusOK
 	}
 
-	return dep != nil, nil
-}
+	var expanded []models.Location
+	var expansions []models.LocationExpansion
+	var tree *locations.Tree
+	seen := make(map[int]bool)
 
-func (s *jobService) jobItemExists(jobItem string) (bool, error) {
-	j, err := s.api.GetJobItems(0, 0, jobItem)
-	if err != nil {
-		return false, err
+	add := func(location models.Location) {
+		if !seen[location.Id] {
+			seen[location.Id] = true
+			expanded = append(expanded, location)
+		}
 	}
 
-	return j != nil, nil
-}
+	for _, location := range requested {
+		if !locations.IsType(location, expansion.From) {
+			add(location)
+			continue
+		}
 
-func (s *jobService) locationsExist(locations []string) (bool, error) {
-	for i := range locations {
-		tempMap := make(map[string]string)
-		tempMap["displayName"] = locations[i]
-		location, err := s.api.GetLocations(tempMap)
-		if err != nil {
-			return false, err
+		if tree == nil {
+			var err error
+			tree, err = s.hierarchy.Tree(ctx)
+			if err != nil {
+				return nil, nil, err, upstreamStatus(err, http.StatusInternalServerError)
+			}
+		}
+
+		descendants := tree.Descendants(location.Id, expansion.Type)
+		if len(descendants) == 0 {
+			return nil, nil, fmt.Errorf("no %slocations found on %s %s", typePrefix(expansion.Type), expansion.From, locations.Name(location)), http.StatusBadRequest
 		}
-		if location == nil {
-			return false, nil
+		for _, descendant := range descendants {
+			add(descendant)
 		}
+		expansions = append(expansions, models.LocationExpansion{Location: location.Id, Locations: idsOf(descendants)})
 	}
 
-	return true, nil
+	return expanded, expansions, nil, http.StatusOK
 }
 
-// CreateJob creates a new job in Optii.
-// It returns the created job if successful and an error (along with the HTTP status code) if there's any issue.
+// jobPlan is everything the rules resolved for a job request, up to the job that is sent to Optii.
+type jobPlan struct {
+	rule       *rules.Rule
+	department *models.Department
+	jobItem    *models.JobItem
+	locations  []models.Location
+	expansions []models.LocationExpansion
+	job        *models.Job
+}
+
+// planJob resolves the department, job item and locations of the request in Optii and applies the matching rule to it.
 // This function uses goroutines to make API calls asynchronously.
-// Currently, a hardcoded newJob *models.Job is created in the "Optii" for testing purposes using Swagger example, but the API may not be functioning correctly.
-func (s *jobService) CreateJob(job *models.CreateJobRequest) (*models.Job, error, int) {
-	departmentResultChan := make(chan bool)
-	jobItemResultChan := make(chan bool)
-	locationsResultChan := make(chan bool)
+func (s *jobService) planJob(ctx context.Context, job *models.CreateJobRequest) (*jobPlan, error, int) {
+	if job.Department == nil {
+		return nil, fmt.Errorf("department is required"), http.StatusBadRequest
+	}
+	if job.JobItem == nil {
+		return nil, fmt.Errorf("job_item is required"), http.StatusBadRequest
+	}
+
+	type lookupResult struct {
+		err        error
+		httpStatus int
+	}
 
-	doAsyncQuery := func(existsFunc func() (bool, error), resultChan chan bool) {
+	departmentResultChan := make(chan lookupResult)
+	jobItemResultChan := make(chan lookupResult)
+	locationsResultChan := make(chan lookupResult)
+
+	doAsyncQuery := func(lookupFunc func() (error, int), resultChan chan lookupResult) {
 		go func() {
-			exists, err := existsFunc()
-			if err != nil || !exists {
-				resultChan <- false
-			} else {
-				resultChan <- true
-			}
+			err, httpStatus := lookupFunc()
+			resultChan <- lookupResult{err: err, httpStatus: httpStatus}
 		}()
 	}
 
-	if job.Department != nil {
-		doAsyncQuery(func() (bool, error) {
-			return s.departmentExists(*job.Department)
-		}, departmentResultChan)
-	}
-
-	if job.JobItem != nil {
-		doAsyncQuery(func() (bool, error) {
-			return s.jobItemExists(*job.JobItem)
-		}, jobItemResultChan)
-	}
-
-	if job.Locations != nil {
-		doAsyncQuery(func() (bool, error) {
-			return s.locationsExist(job.Locations)
-		}, locationsResultChan)
-	}
-
-	departmentExists := <-departmentResultChan
-	jobItemExists := <-jobItemResultChan
-	locationsExist := <-locationsResultChan
-
-	if !departmentExists {
-		return nil, fmt.Errorf("invalid department"), http.StatusBadRequest
-	}
-
-	if !jobItemExists {
-		return nil, fmt.Errorf("invalid job item"), http.StatusBadRequest
-	}
-
-	if !locationsExist {
-		return nil, fmt.Errorf("invalid location"), http.StatusBadRequest
-	}
-
-	var newJob *models.Job
-	newJob = &models.Job{
-		Item: models.Item{
-			Name: "drink",
-		},
-		Priority: "highest",
-		Department: models.Department{
-			Id: 7,
-		},
-		Role: models.Roles{
-			Id: 10,
-		},
-		Location: []models.Location{
-			{
-				Id: 10,
-			},
-		},
-		Action: "deliver",
-		Notes: []models.Notes{
-			{
-				Note: "test",
-			},
-		},
-		Attachments: nil,
-		Assignee: models.Assignee{
-			EmployeeId: 1,
-			Username:   "test",
-			AutoAssign: false,
-		},
-		DueBy: time.Now().Add(time.Hour * 24),
-	}
-
-	switch *job.Department {
-	case "Housekeeping":
-		validJobItems := []string{"Blanket", "Sheets", "Mattress"}
-		if job.JobItem != nil && contains(validJobItems, *job.JobItem) {
-			if checkHousekeepingLocation(job.Locations) {
-				var roomLocations []string
-				for _, loc := range job.Locations {
-					if strings.Contains(strings.ToLower(loc), "room") {
-						roomLocations = append(roomLocations, loc)
-					}
-				}
-
-				var floorLocations []string
-				for _, loc := range job.Locations {
-					if strings.Contains(strings.ToLower(loc), "floor") {
-						floorLocations = append(floorLocations, loc)
-					}
-				}
-
-				// TODO: The swagger documentation and the exercise documentation are confusing
-			} else {
-				return nil, fmt.Errorf("invalid location"), http.StatusBadRequest
-			}
+	plan := &jobPlan{}
+
+	doAsyncQuery(func() (error, int) {
+		var err error
+		var httpStatus int
+		plan.department, err, httpStatus = s.findDepartment(ctx, *job.Department)
+		return err, httpStatus
+	}, departmentResultChan)
+
+	doAsyncQuery(func() (error, int) {
+		var err error
+		var httpStatus int
+		plan.jobItem, err, httpStatus = s.findJobItem(ctx, *job.JobItem)
+		return err, httpStatus
+	}, jobItemResultChan)
+
+	doAsyncQuery(func() (error, int) {
+		var err error
+		var httpStatus int
+		plan.locations, err, httpStatus = s.resolveLocations(ctx, job.Locations)
+		return err, httpStatus
+	}, locationsResultChan)
+
+	results := []lookupResult{<-departmentResultChan, <-jobItemResultChan, <-locationsResultChan}
+	for _, result := range results {
+		if result.err != nil {
+			return nil, result.err, result.httpStatus
 		}
-	case "Engineering":
-		if job.JobItem != nil {
-			if job.Locations != nil && len(job.Locations) > 0 {
-				// TODO: The swagger documentation and the exercise documentation are confusing
-			} else {
-				return nil, fmt.Errorf("at least one location is required for Engineering department"), http.StatusBadRequest
-			}
-		} else {
-			return nil, fmt.Errorf("job item is required for Engineering department"), http.StatusBadRequest
+	}
+
+	var err error
+	var httpStatus int
+	var tree *locations.Tree
+	if needsAncestors(s.rules.Rules()) {
+		tree, err = s.hierarchy.Tree(ctx)
+		if err != nil {
+			return nil, err, upstreamStatus(err, http.StatusInternalServerError)
 		}
-	case "Room Service":
-		if job.JobItem != nil {
-			if job.Locations != nil && len(job.Locations) > 0 {
-				// TODO: The swagger documentation and the exercise documentation are confusing
-			} else {
-				return nil, fmt.Errorf("at least one location is required for Room Service department"), http.StatusBadRequest
-			}
-		} else {
-			return nil, fmt.Errorf("job item is required for Room Service department"), http.StatusBadRequest
+	}
+
+	plan.rule, err = s.rules.Match(ruleInput(departmentName(*plan.department), plan.jobItem.DisplayName, plan.locations, tree))
+	if err != nil {
+		return nil, err, http.StatusBadRequest
+	}
+
+	var expanded []models.Location
+	expanded, plan.expansions, err, httpStatus = s.expandLocations(ctx, plan.rule, plan.locations)
+	if err != nil {
+		return nil, err, httpStatus
+	}
+
+	spec := JobSpec{
+		Department: plan.department,
+		JobItem:    plan.jobItem.DisplayName,
+		Locations:  expanded,
+		Action:     plan.rule.Action,
+		Priority:   plan.rule.Priority,
+	}
+	if job.Description != nil {
+		spec.Description = *job.Description
+	}
+
+	plan.job, err = s.builder.Build(spec)
+	if err != nil {
+		return nil, err, http.StatusBadRequest
+	}
+
+	return plan, nil, http.StatusOK
+}
+
+// CreateJob creates a new job in Optii.
+// It returns the created job if successful and an error (along with the HTTP status code) if there's any issue.
+// When the rule of the job rejects or merges duplicates and an open job already covers the request, the request is
+// refused with a DuplicateJobError or the open job is returned with a 200 status. With an outbox, a job Optii cannot
+// take right now is reported with a JobQueuedError and a 202 status, and one Optii may have received before failing
+// with a JobDeadLetteredError and a 502 status.
+func (s *jobService) CreateJob(ctx context.Context, job *models.CreateJobRequest) (*models.Job, error, int) {
+	plan, err, httpStatus := s.planJob(ctx, job)
+	if err != nil {
+		return nil, err, httpStatus
+	}
+
+	if plan.rule.Duplicates.Policy != rules.DuplicatesCreate {
+		duplicate, err, httpStatus := s.handleDuplicate(ctx, plan)
+		if err != nil || duplicate != nil {
+			return duplicate, err, httpStatus
 		}
 	}
 
-	resp, err := s.api.CreateJob(newJob)
+	if s.outbox != nil {
+		return s.deliver(ctx, plan.job)
+	}
+
+	resp, err := s.api.CreateJob(ctx, plan.job)
 	if err != nil {
-		return nil, err, http.StatusInternalServerError
+		return nil, err, upstreamStatus(err, http.StatusInternalServerError)
 	}
 
 	return resp, nil, http.StatusCreated
 }
 
+// DryRun runs the same validation and rules as CreateJob without creating anything in Optii.
+// It returns the matched rule, the resolved data and the jobs that CreateJob would send.
+func (s *jobService) DryRun(ctx context.Context, job *models.CreateJobRequest) (*models.JobDryRun, error, int) {
+	plan, err, httpStatus := s.planJob(ctx, job)
+	if err != nil {
+		return nil, err, httpStatus
+	}
+
+	dryRun := &models.JobDryRun{
+		Rule:       plan.rule.Name,
+		Department: *plan.department,
+		JobItem:    *plan.jobItem,
+		Locations:  idsOf(plan.locations),
+		Expansions: plan.expansions,
+		Jobs:       []models.Job{*plan.job},
+	}
+
+	if policy := plan.rule.Duplicates.Policy; policy != rules.DuplicatesCreate {
+		duplicate, err := s.findDuplicate(ctx, plan.job, time.Duration(plan.rule.Duplicates.Window))
+		if err != nil {
+			return nil, err, upstreamStatus(err, http.StatusBadGateway)
+		}
+		if duplicate != nil {
+			dryRun.Duplicate = &models.DryRunDuplicate{Policy: policy, JobId: duplicate.Id}
+		}
+	}
+
+	return dryRun, nil, http.StatusOK
+}
+
+// upstreamStatus is the HTTP status reported for a failed Optii call. Requests Optii rejected keep their 4xx status,
+// except for authentication failures which are ours rather than the client's. Optii failures are a bad gateway,
+// calls that ran out of time a gateway timeout and anything else is reported with the fallback status.
+func upstreamStatus(err error, fallback int) int {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout
+	}
+
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) {
+		return fallback
+	}
+
+	switch {
+	case apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden:
+		return http.StatusBadGateway
+	case apiErr.IsClientError():
+		return apiErr.StatusCode
+	default:
+		return http.StatusBadGateway
+	}
+}
+
 func contains(items []string, item string) bool {
 	for _, i := range items {
 		if i == item {
@@ -207,11 +303,48 @@ func contains(items []string, item string) bool {
 	return false
 }
 
-func checkHousekeepingLocation(locations []string) bool {
-	for _, loc := range locations {
-		if strings.Contains(strings.ToLower(loc), "floor") || strings.Contains(strings.ToLower(loc), "room") {
+// ruleInput describes the resolved request to the rules. The names of the locations above the requested ones
+// are only added when a tree is given.
+func ruleInput(department, jobItem string, resolved []models.Location, tree *locations.Tree) rules.Input {
+	input := rules.Input{
+		Department: department,
+		JobItem:    jobItem,
+		Locations:  make([]rules.Location, len(resolved)),
+	}
+	for i, location := range resolved {
+		input.Locations[i] = rules.Location{Name: locations.Name(location)}
+		if location.LocationType != nil {
+			input.Locations[i].Type = location.LocationType.DisplayName
+		}
+		if tree != nil {
+			for _, ancestor := range tree.Ancestors(location.Id) {
+				input.Locations[i].Ancestors = append(input.Locations[i].Ancestors, locations.Name(ancestor))
+			}
+		}
+	}
+	return input
+}
+
+func needsAncestors(all []rules.Rule) bool {
+	for _, rule := range all {
+		if rule.NeedsAncestors() {
 			return true
 		}
 	}
 	return false
 }
+
+func idsOf(locations []models.Location) []int {
+	ids := make([]int, len(locations))
+	for i, location := range locations {
+		ids[i] = location.Id
+	}
+	return ids
+}
+
+func typePrefix(locationType string) string {
+	if locationType == "" {
+		return ""
+	}
+	return locationType + " "
+}
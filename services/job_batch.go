@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"optii/api"
+	"optii/models"
+)
+
+const (
+	// DefaultBatchSize is the largest batch of job requests accepted unless WithBatchLimits is given.
+	DefaultBatchSize = 100
+	// DefaultBatchConcurrency is how many jobs of a batch are created at once unless WithBatchLimits is given.
+	DefaultBatchConcurrency = 4
+)
+
+// JobServiceOption configures the job service.
+type JobServiceOption func(*jobService)
+
+// WithBatchLimits sets the largest batch of job requests and how many of its jobs are created at once.
+func WithBatchLimits(size, concurrency int) JobServiceOption {
+	return func(s *jobService) {
+		s.batchSize = size
+		s.batchConcurrency = max(concurrency, 1)
+	}
+}
+
+// BatchJob is one job request of a batch. Err is set when the entry could not be read, so it fails on its own.
+type BatchJob struct {
+	Request *models.CreateJobRequest
+	Err     error
+}
+
+// CreateJobs creates every job of the batch, reporting the outcome of each of them instead of failing the whole batch.
+// Departments, job items and locations shared by the entries are looked up once. With an idempotency key in ctx,
+// every entry is sent to Optii with its own key derived from it. The batch answers 200 even when entries failed, so
+// its response is kept for the idempotency key and a repeat cannot create the other entries again.
+func (s *jobService) CreateJobs(ctx context.Context, jobs []BatchJob) (*models.BatchJobResponse, error, int) {
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("at least one job is required"), http.StatusBadRequest
+	}
+	if len(jobs) > s.batchSize {
+		return nil, fmt.Errorf("at most %d jobs can be created at once, got %d", s.batchSize, len(jobs)), http.StatusBadRequest
+	}
+
+	batch := *s
+	batch.lookups = &lookupMemo{}
+	key, keyed := api.IdempotencyKey(ctx)
+
+	results := make([]models.BatchJobResult, len(jobs))
+	slots := make(chan struct{}, s.batchConcurrency)
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		if job.Err != nil {
+			results[i] = batchResult(i, nil, job.Err, http.StatusBadRequest)
+			continue
+		}
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int, job *models.CreateJobRequest) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			jobCtx := ctx
+			if keyed {
+				jobCtx = api.WithIdempotencyKey(ctx, fmt.Sprintf("%s-%d", key, i))
+			}
+			created, err, httpStatus := batch.CreateJob(jobCtx, job)
+			results[i] = batchResult(i, created, err, httpStatus)
+		}(i, job.Request)
+	}
+	wg.Wait()
+
+	response := &models.BatchJobResponse{Results: results}
+	for _, result := range results {
+		if result.Status < http.StatusBadRequest {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+	return response, nil, http.StatusOK
+}
+
+func batchResult(index int, job *models.Job, err error, httpStatus int) models.BatchJobResult {
+	result := models.BatchJobResult{Index: index, Status: httpStatus, Job: job}
+	var queued *JobQueuedError
+	switch {
+	case err == nil && httpStatus == http.StatusCreated:
+		result.Outcome = models.BatchJobCreated
+	case err == nil:
+		result.Outcome = models.BatchJobMerged
+	case errors.As(err, &queued):
+		result.Outcome = models.BatchJobQueued
+	case httpStatus < http.StatusInternalServerError:
+		result.Outcome = models.BatchJobRejected
+	default:
+		result.Outcome = models.BatchJobFailed
+	}
+	if err != nil {
+		result.Error = err.Error()
+		var duplicate *DuplicateJobError
+		if errors.As(err, &duplicate) {
+			result.JobId = duplicate.JobId
+		}
+	}
+	return result
+}
+
+// lookupMemo remembers the reference lookups of a batch, so concurrent entries sharing a reference wait for a
+// single lookup.
+type lookupMemo struct {
+	mu      sync.Mutex
+	lookups map[lookupKey]*lookup
+}
+
+type lookupKey struct {
+	entity    string
+	reference models.Reference
+}
+
+type lookup struct {
+	once       sync.Once
+	value      any
+	err        error
+	httpStatus int
+}
+
+// memoized runs find once per entity and reference of the memo. Without a memo it always runs find.
+func memoized[T any](memo *lookupMemo, entity string, reference models.Reference, find func() (*T, error, int)) (*T, error, int) {
+	if memo == nil {
+		return find()
+	}
+
+	memo.mu.Lock()
+	if memo.lookups == nil {
+		memo.lookups = map[lookupKey]*lookup{}
+	}
+	key := lookupKey{entity: entity, reference: reference}
+	l, ok := memo.lookups[key]
+	if !ok {
+		l = &lookup{}
+		memo.lookups[key] = l
+	}
+	memo.mu.Unlock()
+
+	l.once.Do(func() {
+		l.value, l.err, l.httpStatus = find()
+	})
+	value, _ := l.value.(*T)
+	return value, l.err, l.httpStatus
+}
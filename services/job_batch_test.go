@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"optii/api"
+	"optii/locations"
+	"optii/models"
+	"optii/rules"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func engineeringJob(location string) BatchJob {
+	return BatchJob{Request: &models.CreateJobRequest{
+		Department: &models.Reference{Name: "Engineering"},
+		JobItem:    &models.Reference{Name: "Light Bulb"},
+		Locations:  []models.Reference{{Name: location}},
+	}}
+}
+
+func TestCreateJobsReportsEveryOutcome(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	mockRepo.On("GetDepartments", "Engineering", mock.Anything, mock.Anything).
+		Return(&models.Departments{Items: []models.Department{{Id: 5, Name: "Engineering"}}}, nil).Once()
+	mockRepo.On("GetJobItems", mock.Anything, mock.Anything, "Light Bulb").
+		Return(&models.JobItems{Items: []models.JobItem{{Id: 12, DisplayName: "Light Bulb"}}}, nil).Once()
+	mockRepo.On("GetLocations", map[string]string{"displayName": "Room 201", "first": "100"}).
+		Return(&models.Locations{Items: []models.Location{testLocation(201, "Room 201", "Room", 9)}}, nil).Once()
+	mockRepo.On("GetLocations", map[string]string{"displayName": "Room 202", "first": "100"}).
+		Return(&models.Locations{Items: []models.Location{testLocation(202, "Room 202", "Room", 9)}}, nil).Once()
+	mockRepo.On("GetLocations", map[string]string{"displayName": "Room 999", "first": "100"}).
+		Return(&models.Locations{}, nil).Once()
+	mockRepo.On("CreateJob", mock.MatchedBy(func(job *models.Job) bool { return job.Location[0].Id == 201 })).
+		Return(&models.Job{Id: 1}, nil).Twice()
+	mockRepo.On("CreateJob", mock.MatchedBy(func(job *models.Job) bool { return job.Location[0].Id == 202 })).
+		Return((*models.Job)(nil), &api.Error{StatusCode: http.StatusServiceUnavailable}).Once()
+
+	response, err, httpStatus := service.CreateJobs(context.Background(), []BatchJob{
+		engineeringJob("Room 201"),
+		engineeringJob("Room 202"),
+		{Err: errors.New("department is required")},
+		engineeringJob("Room 999"),
+		engineeringJob("Room 201"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, httpStatus)
+	assert.Equal(t, 2, response.Succeeded)
+	assert.Equal(t, 3, response.Failed)
+
+	outcomes := make([]string, len(response.Results))
+	statuses := make([]int, len(response.Results))
+	for i, result := range response.Results {
+		assert.Equal(t, i, result.Index)
+		outcomes[i] = result.Outcome
+		statuses[i] = result.Status
+	}
+	assert.Equal(t, []string{models.BatchJobCreated, models.BatchJobFailed, models.BatchJobRejected, models.BatchJobRejected, models.BatchJobCreated}, outcomes)
+	assert.Equal(t, []int{http.StatusCreated, http.StatusBadGateway, http.StatusBadRequest, http.StatusBadRequest, http.StatusCreated}, statuses)
+	assert.Equal(t, 1, response.Results[0].Job.Id)
+	assert.Equal(t, "department is required", response.Results[2].Error)
+	assert.Equal(t, "invalid location: Room 999", response.Results[3].Error)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateJobsReportsRejectedDuplicates(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newDuplicatesJobService(t, mockRepo, rules.DuplicatesReject)
+	body := mockSpaRequest(mockRepo)
+
+	mockRepo.On("GetJobs", mock.Anything).Return(&models.Jobs{Items: []models.Job{openJob(41, "Sheets", "restock", 301)}}, nil)
+
+	response, _, _ := service.CreateJobs(context.Background(), []BatchJob{{Request: body}})
+	assert.Equal(t, models.BatchJobResult{
+		Status:  http.StatusConflict,
+		Outcome: models.BatchJobRejected,
+		Error:   "job 41 is already open for the same request",
+		JobId:   41,
+	}, response.Results[0])
+}
+
+func TestCreateJobsLimitsTheBatchSize(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	engine, err := rules.NewEngine(rules.Defaults())
+	assert.NoError(t, err)
+	service := NewJobService(mockRepo, engine, NewJobBuilder(), locations.NewIndex(mockRepo), WithBatchLimits(2, 1))
+
+	_, err, httpStatus := service.CreateJobs(context.Background(), []BatchJob{engineeringJob("Room 201"), engineeringJob("Room 202"), engineeringJob("Room 203")})
+	assert.EqualError(t, err, "at most 2 jobs can be created at once, got 3")
+	assert.Equal(t, http.StatusBadRequest, httpStatus)
+
+	_, err, httpStatus = service.CreateJobs(context.Background(), nil)
+	assert.EqualError(t, err, "at least one job is required")
+	assert.Equal(t, http.StatusBadRequest, httpStatus)
+}
+
+func TestCreateJobsReportsQueuedJobs(t *testing.T) {
+	result := batchResult(3, nil, &JobQueuedError{Entry: models.OutboxEntry{Id: "a"}}, http.StatusAccepted)
+	assert.Equal(t, models.BatchJobQueued, result.Outcome)
+	assert.Equal(t, http.StatusAccepted, result.Status)
+}
+
+func TestCreateJobsReportsDeadLetteredJobsAsFailed(t *testing.T) {
+	result := batchResult(3, nil, &JobDeadLetteredError{Entry: models.OutboxEntry{Id: "a", Status: models.OutboxDead}}, http.StatusBadGateway)
+	assert.Equal(t, models.BatchJobFailed, result.Outcome)
+	assert.Equal(t, http.StatusBadGateway, result.Status)
+}
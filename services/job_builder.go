@@ -0,0 +1,103 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"optii/models"
+)
+
+// JobBuilder turns the data resolved by the job rules into the job that is sent to Optii.
+type JobBuilder interface {
+	Build(spec JobSpec) (*models.Job, error)
+}
+
+// JobSpec holds everything the rules resolved for a job.
+type JobSpec struct {
+	Department  *models.Department
+	JobItem     string
+	Locations   []models.Location
+	Action      string
+	Priority    string
+	Description string
+}
+
+// JobValidationError is returned by JobBuilder, or by job updates, when a field of the job is missing or invalid.
+type JobValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *JobValidationError) Error() string {
+	return fmt.Sprintf("invalid job %s: %s", e.Field, e.Reason)
+}
+
+const defaultPriority = "medium"
+
+var jobPriorities = []string{"lowest", "low", "medium", "high", "highest"}
+
+type jobBuilder struct {
+	now   func() time.Time
+	dueIn time.Duration
+}
+
+func NewJobBuilder() JobBuilder {
+	return &jobBuilder{
+		now:   time.Now,
+		dueIn: time.Hour * 24,
+	}
+}
+
+func (b *jobBuilder) Build(spec JobSpec) (*models.Job, error) {
+	if spec.Department == nil || spec.Department.Id <= 0 {
+		return nil, &JobValidationError{Field: "department", Reason: "a resolved department id is required"}
+	}
+
+	if spec.JobItem == "" {
+		return nil, &JobValidationError{Field: "job_item", Reason: "a job item name is required"}
+	}
+
+	if spec.Action == "" {
+		return nil, &JobValidationError{Field: "action", Reason: "an action is required"}
+	}
+
+	priority := spec.Priority
+	if priority == "" {
+		priority = defaultPriority
+	}
+	if !contains(jobPriorities, priority) {
+		return nil, &JobValidationError{Field: "priority", Reason: fmt.Sprintf("%s is not one of %v", priority, jobPriorities)}
+	}
+
+	if len(spec.Locations) == 0 {
+		return nil, &JobValidationError{Field: "locations", Reason: "at least one location is required"}
+	}
+
+	locations := make([]models.Location, len(spec.Locations))
+	for i, location := range spec.Locations {
+		if location.Id <= 0 {
+			return nil, &JobValidationError{Field: "locations", Reason: "every location needs a resolved id"}
+		}
+		locations[i] = models.Location{Id: location.Id}
+	}
+
+	notes := []models.Notes{}
+	if spec.Description != "" {
+		notes = append(notes, models.Notes{Note: spec.Description})
+	}
+
+	return &models.Job{
+		Item: models.Item{
+			Name: spec.JobItem,
+		},
+		Priority:   priority,
+		Action:     spec.Action,
+		Department: models.Department{Id: spec.Department.Id},
+		Location:   locations,
+		Notes:      notes,
+		Assignee: models.Assignee{
+			AutoAssign: true,
+		},
+		DueBy: b.now().Add(b.dueIn),
+	}, nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"optii/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobBuilderBuild(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	builder := &jobBuilder{now: func() time.Time { return now }, dueIn: time.Hour}
+
+	room := testLocation(101, "Room 101", "Room", 1)
+	job, err := builder.Build(JobSpec{
+		Department:  &models.Department{Id: 3, Name: "Housekeeping"},
+		JobItem:     "Sheets",
+		Locations:   []models.Location{room},
+		Action:      "clean",
+		Description: "Guest request",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Job{
+		Item:       models.Item{Name: "Sheets"},
+		Priority:   "medium",
+		Action:     "clean",
+		Department: models.Department{Id: 3},
+		Location:   []models.Location{{Id: 101}},
+		Notes:      []models.Notes{{Note: "Guest request"}},
+		Assignee:   models.Assignee{AutoAssign: true},
+		DueBy:      now.Add(time.Hour),
+	}, job)
+}
+
+func TestJobBuilderBuildValidation(t *testing.T) {
+	valid := func() JobSpec {
+		return JobSpec{
+			Department: &models.Department{Id: 3},
+			JobItem:    "Sheets",
+			Locations:  []models.Location{{Id: 101}},
+			Action:     "clean",
+		}
+	}
+
+	tests := []struct {
+		name  string
+		edit  func(spec *JobSpec)
+		field string
+	}{
+		{"missing department", func(spec *JobSpec) { spec.Department = nil }, "department"},
+		{"unresolved department", func(spec *JobSpec) { spec.Department = &models.Department{Name: "Housekeeping"} }, "department"},
+		{"missing job item", func(spec *JobSpec) { spec.JobItem = "" }, "job_item"},
+		{"missing action", func(spec *JobSpec) { spec.Action = "" }, "action"},
+		{"unknown priority", func(spec *JobSpec) { spec.Priority = "urgent" }, "priority"},
+		{"missing locations", func(spec *JobSpec) { spec.Locations = nil }, "locations"},
+		{"unresolved location", func(spec *JobSpec) { spec.Locations = []models.Location{{}} }, "locations"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := valid()
+			tt.edit(&spec)
+
+			job, err := NewJobBuilder().Build(spec)
+			assert.Nil(t, job)
+
+			var validationErr *JobValidationError
+			if assert.True(t, errors.As(err, &validationErr)) {
+				assert.Equal(t, tt.field, validationErr.Field)
+			}
+		})
+	}
+}
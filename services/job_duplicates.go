@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"optii/api"
+	"optii/models"
+	"optii/rules"
+)
+
+// DuplicateJobError is returned when an open job already covers a job request and the rule rejects duplicates.
+type DuplicateJobError struct {
+	JobId int
+}
+
+func (e *DuplicateJobError) Error() string {
+	return fmt.Sprintf("job %d is already open for the same request", e.JobId)
+}
+
+// openJobStatuses are the statuses of jobs that still have to be done.
+var openJobStatuses = []models.JobStatus{models.JobStatusNew, models.JobStatusPending, models.JobStatusInProgress, models.JobStatusOnHold}
+
+// handleDuplicate applies the duplicate policy of the matched rule. It returns the open job the request was merged
+// into, an error when the request is rejected, or nothing when the job is to be created.
+func (s *jobService) handleDuplicate(ctx context.Context, plan *jobPlan) (*models.Job, error, int) {
+	duplicate, err := s.findDuplicate(ctx, plan.job, time.Duration(plan.rule.Duplicates.Window))
+	if err != nil {
+		return nil, err, upstreamStatus(err, http.StatusBadGateway)
+	}
+	if duplicate == nil {
+		return nil, nil, http.StatusOK
+	}
+
+	if plan.rule.Duplicates.Policy == rules.DuplicatesReject {
+		return nil, &DuplicateJobError{JobId: duplicate.Id}, http.StatusConflict
+	}
+
+	if _, err := s.api.AddJobNote(ctx, duplicate.Id, mergeNote(plan.job)); err != nil {
+		return nil, err, upstreamStatus(err, http.StatusBadGateway)
+	}
+	return duplicate, nil, http.StatusOK
+}
+
+// findDuplicate returns an open job created within the window with the department, job item and action of the
+// given job and covering all of its locations.
+func (s *jobService) findDuplicate(ctx context.Context, job *models.Job, window time.Duration) (*models.Job, error) {
+	since := s.now().Add(-window)
+
+	statuses := make([]string, len(openJobStatuses))
+	for i, status := range openJobStatuses {
+		statuses[i] = string(status)
+	}
+	params := map[string]string{
+		"departmentId": strconv.Itoa(job.Department.Id),
+		"status":       strings.Join(statuses, ","),
+	}
+	setTime(params, "createdFrom", &since)
+	if len(job.Location) > 0 {
+		params["locationId"] = strconv.Itoa(job.Location[0].Id)
+	}
+
+	jobs := api.IterateJobs(ctx, s.api, params)
+	for jobs.Next() {
+		candidate := jobs.Item()
+		if isDuplicate(&candidate, job, since) {
+			return &candidate, nil
+		}
+	}
+	return nil, jobs.Err()
+}
+
+// isDuplicate checks the candidate against the job again, so filters Optii does not apply are still honoured.
+func isDuplicate(candidate, job *models.Job, since time.Time) bool {
+	if candidate.Department.Id != job.Department.Id ||
+		!strings.EqualFold(candidate.Item.Name, job.Item.Name) ||
+		!strings.EqualFold(candidate.Action, job.Action) {
+		return false
+	}
+	if candidate.Status != "" && !isOpen(candidate.Status) {
+		return false
+	}
+	if candidate.CreatedAt != nil && candidate.CreatedAt.Before(since) {
+		return false
+	}
+
+	covered := make(map[int]bool, len(candidate.Location))
+	for _, location := range candidate.Location {
+		covered[location.Id] = true
+	}
+	for _, location := range job.Location {
+		if !covered[location.Id] {
+			return false
+		}
+	}
+	return true
+}
+
+func isOpen(status models.JobStatus) bool {
+	for _, open := range openJobStatuses {
+		if status == open {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeNote is the note added to an open job when the same request comes in again.
+func mergeNote(job *models.Job) string {
+	for _, note := range job.Notes {
+		if note.Note != "" {
+			return "Requested again: " + note.Note
+		}
+	}
+	return "Requested again"
+}
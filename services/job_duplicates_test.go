@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"optii/locations"
+	"optii/models"
+	"optii/rules"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newDuplicatesJobService returns a job service whose Spa requests create restock jobs with the given duplicate policy.
+func newDuplicatesJobService(t *testing.T, mockRepo *JobRepositoryMock, policy string) *jobService {
+	engine, err := rules.NewEngine(fstest.MapFS{
+		"spa.yaml": {Data: []byte("name: spa-restock\ndepartment: Spa\naction: restock\nduplicates:\n  policy: " + policy + "\n  window: 2h\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return &jobService{
+		api:       mockRepo,
+		rules:     engine,
+		builder:   NewJobBuilder(),
+		hierarchy: locations.NewIndex(mockRepo),
+		now:       func() time.Time { return now },
+
+		batchSize:        DefaultBatchSize,
+		batchConcurrency: DefaultBatchConcurrency,
+	}
+}
+
+func mockSpaRequest(mockRepo *JobRepositoryMock) *models.CreateJobRequest {
+	room := testLocation(301, "Room 301", "Room", 3)
+	mockRepo.On("GetDepartment", 7).Return(&models.Department{Id: 7, Name: "Spa"}, nil)
+	mockRepo.On("GetJobItem", 12).Return(&models.JobItem{Id: 12, DisplayName: "Sheets"}, nil)
+	mockRepo.On("GetLocation", 301).Return(&room, nil)
+
+	description := "Guest asked twice"
+	return &models.CreateJobRequest{
+		Department:  &models.Reference{Id: 7},
+		JobItem:     &models.Reference{Id: 12},
+		Locations:   []models.Reference{{Id: 301}},
+		Description: &description,
+	}
+}
+
+func openJob(id int, item, action string, locationIds ...int) models.Job {
+	job := models.Job{Id: id, Action: action, Item: models.Item{Name: item}, Department: models.Department{Id: 7}, Status: models.JobStatusPending}
+	for _, locationId := range locationIds {
+		job.Location = append(job.Location, models.Location{Id: locationId})
+	}
+	return job
+}
+
+func TestCreateJobRejectsDuplicates(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newDuplicatesJobService(t, mockRepo, rules.DuplicatesReject)
+	body := mockSpaRequest(mockRepo)
+
+	mockRepo.On("GetJobs", map[string]string{
+		"departmentId": "7",
+		"locationId":   "301",
+		"status":       "new,pending,inProgress,onHold",
+		"createdFrom":  "2024-05-01T10:00:00Z",
+		"first":        "100",
+	}).Return(&models.Jobs{Items: []models.Job{
+		openJob(40, "Towels", "restock", 301),
+		openJob(41, "sheets", "restock", 301, 302),
+	}}, nil).Once()
+
+	_, err, httpStatus := service.CreateJob(context.Background(), body)
+	assert.Equal(t, &DuplicateJobError{JobId: 41}, err)
+	assert.Equal(t, http.StatusConflict, httpStatus)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "CreateJob", mock.Anything)
+}
+
+func TestCreateJobMergesDuplicates(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newDuplicatesJobService(t, mockRepo, rules.DuplicatesMerge)
+	body := mockSpaRequest(mockRepo)
+
+	mockRepo.On("GetJobs", mock.Anything).Return(&models.Jobs{Items: []models.Job{openJob(41, "Sheets", "restock", 301)}}, nil).Once()
+	mockRepo.On("AddJobNote", 41, "Requested again: Guest asked twice").Return(&models.Notes{Id: 5}, nil).Once()
+
+	job, err, httpStatus := service.CreateJob(context.Background(), body)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, httpStatus)
+	assert.Equal(t, 41, job.Id)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "CreateJob", mock.Anything)
+}
+
+func TestCreateJobWithoutDuplicates(t *testing.T) {
+	closed := openJob(42, "Sheets", "restock", 301)
+	closed.Status = models.JobStatusCompleted
+	old := openJob(43, "Sheets", "restock", 301)
+	createdAt := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	old.CreatedAt = &createdAt
+
+	mockRepo := new(JobRepositoryMock)
+	service := newDuplicatesJobService(t, mockRepo, rules.DuplicatesMerge)
+	body := mockSpaRequest(mockRepo)
+
+	mockRepo.On("GetJobs", mock.Anything).Return(&models.Jobs{Items: []models.Job{
+		openJob(40, "Sheets", "clean", 301),
+		openJob(41, "Sheets", "restock", 302),
+		closed,
+		old,
+	}}, nil).Once()
+	mockRepo.On("CreateJob", mock.Anything).Return(&models.Job{Id: 44}, nil).Once()
+
+	job, err, httpStatus := service.CreateJob(context.Background(), body)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, httpStatus)
+	assert.Equal(t, 44, job.Id)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "AddJobNote", mock.Anything, mock.Anything)
+}
+
+func TestDryRunReportsDuplicates(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newDuplicatesJobService(t, mockRepo, rules.DuplicatesMerge)
+	body := mockSpaRequest(mockRepo)
+
+	mockRepo.On("GetJobs", mock.Anything).Return(&models.Jobs{Items: []models.Job{openJob(41, "Sheets", "restock", 301)}}, nil).Once()
+
+	dryRun, err, httpStatus := service.DryRun(context.Background(), body)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, httpStatus)
+	assert.Equal(t, &models.DryRunDuplicate{Policy: rules.DuplicatesMerge, JobId: 41}, dryRun.Duplicate)
+	assert.Len(t, dryRun.Jobs, 1)
+
+	mockRepo.AssertNotCalled(t, "AddJobNote", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "CreateJob", mock.Anything)
+}
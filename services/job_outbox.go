@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"optii/models"
+	"optii/outbox"
+)
+
+// WithOutbox saves every job in the outbox before it is sent to Optii, so jobs are still delivered when Optii is down.
+func WithOutbox(outbox outbox.Outbox) JobServiceOption {
+	return func(s *jobService) {
+		s.outbox = outbox
+	}
+}
+
+// JobQueuedError is returned when Optii could not take a job, which stays in the outbox until it is delivered.
+type JobQueuedError struct {
+	Entry models.OutboxEntry
+}
+
+func (e *JobQueuedError) Error() string {
+	return fmt.Sprintf("Optii is unavailable, the job is queued as outbox entry %s: %s", e.Entry.Id, e.Entry.LastError)
+}
+
+// JobDeadLetteredError is returned when sending a job failed after Optii may have received it. The job is not sent
+// again but dead-lettered, to be checked in Optii before it is replayed.
+type JobDeadLetteredError struct {
+	Entry models.OutboxEntry
+}
+
+func (e *JobDeadLetteredError) Error() string {
+	return fmt.Sprintf("Optii may have received the job, which is dead-lettered as outbox entry %s: %s", e.Entry.Id, e.Entry.LastError)
+}
+
+// deliver sends the job through the outbox.
+func (s *jobService) deliver(ctx context.Context, job *models.Job) (*models.Job, error, int) {
+	created, entry, err := s.outbox.Deliver(ctx, job)
+	if err != nil {
+		return nil, err, upstreamStatus(err, http.StatusInternalServerError)
+	}
+	if created == nil && entry.Status == models.OutboxDead {
+		return nil, &JobDeadLetteredError{Entry: *entry}, http.StatusBadGateway
+	}
+	if created == nil {
+		return nil, &JobQueuedError{Entry: *entry}, http.StatusAccepted
+	}
+	return created, nil, http.StatusCreated
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"optii/api"
+	"optii/locations"
+	"optii/models"
+	"optii/outbox"
+	"optii/rules"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// stubOutbox delivers jobs with the given function.
+type stubOutbox struct {
+	outbox.Outbox
+	deliver func(job *models.Job) (*models.Job, *models.OutboxEntry, error)
+}
+
+func (o *stubOutbox) Deliver(ctx context.Context, job *models.Job) (*models.Job, *models.OutboxEntry, error) {
+	return o.deliver(job)
+}
+
+func TestCreateJobGoesThroughTheOutbox(t *testing.T) {
+	tests := []struct {
+		name       string
+		created    *models.Job
+		entry      *models.OutboxEntry
+		err        error
+		httpStatus int
+	}{
+		{"delivered", &models.Job{Id: 41}, &models.OutboxEntry{Id: "a", Status: models.OutboxDelivered}, nil, http.StatusCreated},
+		{"queued", nil, &models.OutboxEntry{Id: "a", Status: models.OutboxPending, LastError: "optii down"}, nil, http.StatusAccepted},
+		{"dead-lettered", nil, &models.OutboxEntry{Id: "a", Status: models.OutboxDead, LastError: "optii timed out"}, nil, http.StatusBadGateway},
+		{"rejected", nil, nil, &api.Error{StatusCode: http.StatusUnprocessableEntity}, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(JobRepositoryMock)
+			engine, err := rules.NewEngine(rules.Defaults())
+			assert.NoError(t, err)
+
+			var sent *models.Job
+			box := &stubOutbox{deliver: func(job *models.Job) (*models.Job, *models.OutboxEntry, error) {
+				sent = job
+				return tt.created, tt.entry, tt.err
+			}}
+			service := NewJobService(mockRepo, engine, NewJobBuilder(), locations.NewIndex(mockRepo), WithOutbox(box))
+
+			room := testLocation(101, "Room 101", "Room", 1)
+			mockRepo.On("GetDepartment", 7).Return(&models.Department{Id: 7, Name: "Room Service"}, nil)
+			mockRepo.On("GetJobItem", 12).Return(&models.JobItem{Id: 12, DisplayName: "Towels"}, nil)
+			mockRepo.On("GetLocation", 101).Return(&room, nil)
+
+			job, err, httpStatus := service.CreateJob(context.Background(), &models.CreateJobRequest{
+				Department: &models.Reference{Id: 7},
+				JobItem:    &models.Reference{Id: 12},
+				Locations:  []models.Reference{{Id: 101}},
+			})
+			assert.Equal(t, tt.httpStatus, httpStatus)
+			assert.Equal(t, "deliver", sent.Action)
+			assert.Equal(t, tt.created, job)
+			switch tt.name {
+			case "queued":
+				assert.Equal(t, &JobQueuedError{Entry: *tt.entry}, err)
+				assert.EqualError(t, err, "Optii is unavailable, the job is queued as outbox entry a: optii down")
+			case "dead-lettered":
+				assert.Equal(t, &JobDeadLetteredError{Entry: *tt.entry}, err)
+				assert.EqualError(t, err, "Optii may have received the job, which is dead-lettered as outbox entry a: optii timed out")
+			case "rejected":
+				assert.Equal(t, tt.err, err)
+			default:
+				assert.NoError(t, err)
+			}
+			mockRepo.AssertNotCalled(t, "CreateJob", mock.Anything)
+		})
+	}
+}
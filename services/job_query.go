@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"optii/models"
+)
+
+// JobQuery filters the jobs of Optii. Unset fields do not filter.
+type JobQuery struct {
+	Department *models.Reference
+	Location   *models.Reference
+	Statuses   []models.JobStatus
+	Assignee   int
+	// DueFrom and DueTo bound the due date of the jobs, both inclusive.
+	DueFrom *time.Time
+	DueTo   *time.Time
+	// CreatedFrom and CreatedTo bound the creation date of the jobs, both inclusive.
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	First       int
+	Next        int
+}
+
+// maxJobsPageSize is the largest page of jobs a client may ask for.
+const maxJobsPageSize = 100
+
+// GetJob returns the job with the given id.
+func (s *jobService) GetJob(ctx context.Context, id int) (*models.Job, error, int) {
+	return proxied(s.api.GetJob(ctx, id))
+}
+
+// ListJobs returns a page of the jobs matching the query. Departments and locations given by name are resolved
+// to their id first, just like in job requests.
+func (s *jobService) ListJobs(ctx context.Context, query JobQuery) (*models.Jobs, error, int) {
+	if err := query.validate(); err != nil {
+		return nil, err, http.StatusBadRequest
+	}
+
+	params := map[string]string{}
+	if query.Department != nil {
+		department, err, httpStatus := s.findDepartment(ctx, *query.Department)
+		if err != nil {
+			return nil, err, httpStatus
+		}
+		params["departmentId"] = strconv.Itoa(department.Id)
+	}
+	if query.Location != nil {
+		location, err, httpStatus := s.findLocation(ctx, *query.Location)
+		if err != nil {
+			return nil, err, httpStatus
+		}
+		params["locationId"] = strconv.Itoa(location.Id)
+	}
+	if len(query.Statuses) > 0 {
+		statuses := make([]string, len(query.Statuses))
+		for i, status := range query.Statuses {
+			statuses[i] = string(status)
+		}
+		params["status"] = strings.Join(statuses, ",")
+	}
+	if query.Assignee > 0 {
+		params["assigneeId"] = strconv.Itoa(query.Assignee)
+	}
+	setTime(params, "dueByFrom", query.DueFrom)
+	setTime(params, "dueByTo", query.DueTo)
+	setTime(params, "createdFrom", query.CreatedFrom)
+	setTime(params, "createdTo", query.CreatedTo)
+	if query.First > 0 {
+		params["first"] = strconv.Itoa(query.First)
+	}
+	if query.Next > 0 {
+		params["next"] = strconv.Itoa(query.Next)
+	}
+
+	return proxied(s.api.GetJobs(ctx, params))
+}
+
+func (q JobQuery) validate() error {
+	if q.Assignee < 0 {
+		return fmt.Errorf("invalid assignee %d", q.Assignee)
+	}
+	if q.First < 0 || q.First > maxJobsPageSize {
+		return fmt.Errorf("first must be between 1 and %d", maxJobsPageSize)
+	}
+	if q.Next < 0 {
+		return fmt.Errorf("invalid next %d", q.Next)
+	}
+	if q.DueFrom != nil && q.DueTo != nil && q.DueFrom.After(*q.DueTo) {
+		return fmt.Errorf("dueFrom %s is after dueTo %s", q.DueFrom.Format(time.RFC3339), q.DueTo.Format(time.RFC3339))
+	}
+	if q.CreatedFrom != nil && q.CreatedTo != nil && q.CreatedFrom.After(*q.CreatedTo) {
+		return fmt.Errorf("createdFrom %s is after createdTo %s", q.CreatedFrom.Format(time.RFC3339), q.CreatedTo.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func setTime(params map[string]string, key string, value *time.Time) {
+	if value != nil {
+		params[key] = value.UTC().Format(time.RFC3339)
+	}
+}
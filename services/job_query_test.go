@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"optii/api"
+	"optii/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListJobsTranslatesFilters(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	mockRepo.On("GetDepartments", "Housekeeping", 100, 0).
+		Return(&models.Departments{Items: []models.Department{{Id: 3, Name: "Housekeeping"}}}, nil).Once()
+	room := testLocation(101, "Room 101", "Room", 1)
+	mockRepo.On("GetLocation", 101).Return(&room, nil).Once()
+
+	jobs := &models.Jobs{Items: []models.Job{{Id: 55}}, PageInfo: models.PageInfo{EndCursor: 20, HasNextPage: true}}
+	mockRepo.On("GetJobs", map[string]string{
+		"departmentId": "3",
+		"locationId":   "101",
+		"status":       "new,inProgress",
+		"assigneeId":   "8",
+		"dueByFrom":    "2024-05-01T00:00:00Z",
+		"dueByTo":      "2024-05-01T22:00:00Z",
+		"createdFrom":  "2024-04-30T12:00:00Z",
+		"first":        "20",
+		"next":         "40",
+	}).Return(jobs, nil).Once()
+
+	dueFrom := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	dueTo := time.Date(2024, 5, 2, 0, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	createdFrom := time.Date(2024, 4, 30, 12, 0, 0, 0, time.UTC)
+
+	result, err, httpStatus := service.ListJobs(context.Background(), JobQuery{
+		Department:  &models.Reference{Name: "Housekeeping"},
+		Location:    &models.Reference{Id: 101},
+		Statuses:    []models.JobStatus{models.JobStatusNew, models.JobStatusInProgress},
+		Assignee:    8,
+		DueFrom:     &dueFrom,
+		DueTo:       &dueTo,
+		CreatedFrom: &createdFrom,
+		First:       20,
+		Next:        40,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, httpStatus)
+	assert.Equal(t, jobs, result)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestListJobsWithoutFilters(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	mockRepo.On("GetJobs", map[string]string{}).Return(&models.Jobs{}, nil).Once()
+
+	_, err, httpStatus := service.ListJobs(context.Background(), JobQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, httpStatus)
+}
+
+func TestListJobsRejectsInvalidQueries(t *testing.T) {
+	later := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	earlier := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]JobQuery{
+		"due range":     {DueFrom: &later, DueTo: &earlier},
+		"created range": {CreatedFrom: &later, CreatedTo: &earlier},
+		"page size":     {First: 500},
+		"cursor":        {Next: -1},
+		"assignee":      {Assignee: -4},
+	}
+
+	for name, query := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(JobRepositoryMock)
+			service := newTestJobService(t, mockRepo)
+
+			_, err, httpStatus := service.ListJobs(context.Background(), query)
+			assert.Error(t, err)
+			assert.Equal(t, http.StatusBadRequest, httpStatus)
+			mockRepo.AssertNotCalled(t, "GetJobs")
+		})
+	}
+}
+
+func TestListJobsRejectsUnknownDepartment(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	mockRepo.On("GetDepartments", "Spa", 100, 0).Return(&models.Departments{}, nil).Once()
+
+	_, err, httpStatus := service.ListJobs(context.Background(), JobQuery{Department: &models.Reference{Name: "Spa"}})
+	assert.EqualError(t, err, "invalid department: Spa")
+	assert.Equal(t, http.StatusBadRequest, httpStatus)
+	mockRepo.AssertNotCalled(t, "GetJobs")
+}
+
+func TestGetJobKeepsNotFound(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	mockRepo.On("GetJob", 404).Return((*models.Job)(nil), &api.Error{StatusCode: http.StatusNotFound}).Once()
+	mockRepo.On("GetJob", 55).Return(&models.Job{Id: 55, Status: models.JobStatusPending}, nil).Once()
+
+	_, _, httpStatus := service.GetJob(context.Background(), 404)
+	assert.Equal(t, http.StatusNotFound, httpStatus)
+
+	job, err, httpStatus := service.GetJob(context.Background(), 55)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, httpStatus)
+	assert.Equal(t, models.JobStatusPending, job.Status)
+}
@@ -1,102 +1,135 @@
 package services
 
 import (
+	"context"
 	"net/http"
 	"testing"
+	"testing/fstest"
 
+	"optii/api"
+	"optii/locations"
 	"optii/models"
+	"optii/rules"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+func newTestJobService(t *testing.T, api api.OptiiApi) JobService {
+	engine, err := rules.NewEngine(rules.Defaults())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewJobService(api, engine, NewJobBuilder(), locations.NewIndex(api))
+}
+
 type JobRepositoryMock struct {
 	mock.Mock
 }
 
-func (m *JobRepositoryMock) GetDepartment(id int) (*models.Department, error) {
+func (m *JobRepositoryMock) GetDepartment(ctx context.Context, id int) (*models.Department, error) {
 	args := m.Called(id)
 	return args.Get(0).(*models.Department), args.Error(1)
 }
 
-func (m *JobRepositoryMock) GetDepartments(displayName string, first, next int) (*models.Departments, error) {
+func (m *JobRepositoryMock) GetDepartments(ctx context.Context, displayName string, first, next int) (*models.Departments, error) {
 	args := m.Called(displayName, first, next)
 	return args.Get(0).(*models.Departments), args.Error(1)
 }
 
-func (m *JobRepositoryMock) GetLocation(locationId int) (*models.Location, error) {
+func (m *JobRepositoryMock) GetLocation(ctx context.Context, locationId int) (*models.Location, error) {
 	args := m.Called(locationId)
 	return args.Get(0).(*models.Location), args.Error(1)
 }
 
-func (m *JobRepositoryMock) GetLocations(params map[string]string) (*models.Locations, error) {
+func (m *JobRepositoryMock) GetLocations(ctx context.Context, params map[string]string) (*models.Locations, error) {
 	args := m.Called(params)
 	return args.Get(0).(*models.Locations), args.Error(1)
 }
 
-func (m *JobRepositoryMock) GetLocationTypes() (*models.LocationTypes, error) {
-	args := m.Called()
+func (m *JobRepositoryMock) GetLocationTypes(ctx context.Context, first, next int) (*models.LocationTypes, error) {
+	args := m.Called(first, next)
 	return args.Get(0).(*models.LocationTypes), args.Error(1)
 }
 
-func (m *JobRepositoryMock) GetLocationType(locationTypeId int) (*models.LocationType, error) {
+func (m *JobRepositoryMock) GetLocationType(ctx context.Context, locationTypeId int) (*models.LocationType, error) {
 	args := m.Called(locationTypeId)
 	return args.Get(0).(*models.LocationType), args.Error(1)
 }
 
-func (m *JobRepositoryMock) GetJobItem(jobItemId int) (*models.JobItem, error) {
+func (m *JobRepositoryMock) GetJobItem(ctx context.Context, jobItemId int) (*models.JobItem, error) {
 	args := m.Called(jobItemId)
 	return args.Get(0).(*models.JobItem), args.Error(1)
 }
 
-func (m *JobRepositoryMock) GetJobItems(first int, next int, displayName string) (*models.JobItems, error) {
+func (m *JobRepositoryMock) GetJobItems(ctx context.Context, first int, next int, displayName string) (*models.JobItems, error) {
 	args := m.Called(first, next, displayName)
 	return args.Get(0).(*models.JobItems), args.Error(1)
 }
 
-func (m *JobRepositoryMock) GetJob(jobId int) (*models.Job, error) {
+func (m *JobRepositoryMock) GetJob(ctx context.Context, jobId int) (*models.Job, error) {
 	args := m.Called(jobId)
 	return args.Get(0).(*models.Job), args.Error(1)
 }
 
-func (m *JobRepositoryMock) GetJobs(params map[string]string) (*models.Jobs, error) {
+func (m *JobRepositoryMock) UpdateJob(ctx context.Context, jobId int, patch *models.JobPatch) (*models.Job, error) {
+	args := m.Called(jobId, patch)
+	return args.Get(0).(*models.Job), args.Error(1)
+}
+
+func (m *JobRepositoryMock) TransitionJob(ctx context.Context, jobId int, status models.JobStatus) (*models.Job, error) {
+	args := m.Called(jobId, status)
+	return args.Get(0).(*models.Job), args.Error(1)
+}
+
+func (m *JobRepositoryMock) CancelJob(ctx context.Context, jobId int, reason string) (*models.Job, error) {
+	args := m.Called(jobId, reason)
+	return args.Get(0).(*models.Job), args.Error(1)
+}
+
+func (m *JobRepositoryMock) AddJobNote(ctx context.Context, jobId int, note string) (*models.Notes, error) {
+	args := m.Called(jobId, note)
+	return args.Get(0).(*models.Notes), args.Error(1)
+}
+
+func (m *JobRepositoryMock) GetJobs(ctx context.Context, params map[string]string) (*models.Jobs, error) {
 	args := m.Called(params)
 	return args.Get(0).(*models.Jobs), args.Error(1)
 }
 
-func (m *JobRepositoryMock) CreateJob(jobData *models.Job) (*models.Job, error) {
+func (m *JobRepositoryMock) CreateJob(ctx context.Context, jobData *models.Job) (*models.Job, error) {
 	args := m.Called(jobData)
 	return args.Get(0).(*models.Job), args.Error(1)
 }
 
 func TestCreateJob(t *testing.T) {
 	mockRepo := new(JobRepositoryMock)
-	service := jobService{api: mockRepo}
+	service := newTestJobService(t, mockRepo)
 
-	var desc, depart, jobItem = "test", "test", "test"
-	var location []string
-	location = append(location, "test")
+	var desc, depart, jobItem = "test", "Room Service", "test"
+	var location []models.Reference
+	location = append(location, models.Reference{Name: "test"})
 
 	body := models.CreateJobRequest{
 		Description: &desc,
-		Department:  &depart,
-		JobItem:     &jobItem,
+		Department:  &models.Reference{Name: depart},
+		JobItem:     &models.Reference{Name: jobItem},
 		Locations:   location,
 	}
 
-	dep := &models.Departments{}
+	dep := &models.Departments{Items: []models.Department{{Id: 7, Name: "Room Service"}}}
 	mockRepo.On("GetDepartments", mock.Anything, mock.Anything, mock.Anything).Return(dep, nil).Once()
 
-	loc := &models.Locations{}
+	loc := &models.Locations{Items: []models.Location{testLocation(1, "test", "Room", 0)}}
 	mockRepo.On("GetLocations", mock.Anything).Return(loc, nil).Once()
 
-	item := &models.JobItems{}
+	item := &models.JobItems{Items: []models.JobItem{{Id: 12, DisplayName: jobItem}}}
 	mockRepo.On("GetJobItems", mock.Anything, mock.Anything, mock.Anything).Return(item, nil).Once()
 
 	job := &models.Job{}
 	mockRepo.On("CreateJob", mock.Anything).Return(job, nil).Once()
 
-	result, err, httpStatusCode := service.CreateJob(&body)
+	result, err, httpStatusCode := service.CreateJob(context.Background(), &body)
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, http.StatusCreated, httpStatusCode)
@@ -106,29 +139,29 @@ func TestCreateJob(t *testing.T) {
 
 func TestCreateJobWithNilDepartment(t *testing.T) {
 	mockRepo := new(JobRepositoryMock)
-	service := jobService{api: mockRepo}
+	service := newTestJobService(t, mockRepo)
 
 	var desc, depart, jobItem = "test", "test", "test"
-	var location []string
-	location = append(location, "test")
+	var location []models.Reference
+	location = append(location, models.Reference{Name: "test"})
 
 	body := models.CreateJobRequest{
 		Description: &desc,
-		Department:  &depart,
-		JobItem:     &jobItem,
+		Department:  &models.Reference{Name: depart},
+		JobItem:     &models.Reference{Name: jobItem},
 		Locations:   location,
 	}
 
 	var dep *models.Departments
 	mockRepo.On("GetDepartments", mock.Anything, mock.Anything, mock.Anything).Return(dep, nil).Once()
 
-	loc := &models.Locations{}
+	loc := &models.Locations{Items: []models.Location{testLocation(1, "test", "Room", 0)}}
 	mockRepo.On("GetLocations", mock.Anything).Return(loc, nil).Once()
 
-	item := &models.JobItems{}
+	item := &models.JobItems{Items: []models.JobItem{{Id: 12, DisplayName: jobItem}}}
 	mockRepo.On("GetJobItems", mock.Anything, mock.Anything, mock.Anything).Return(item, nil).Once()
 
-	_, err, httpStatusCode := service.CreateJob(&body)
+	_, err, httpStatusCode := service.CreateJob(context.Background(), &body)
 	assert.NotNil(t, err)
 	assert.Equal(t, http.StatusBadRequest, httpStatusCode)
 
@@ -137,29 +170,29 @@ func TestCreateJobWithNilDepartment(t *testing.T) {
 
 func TestCreateJobWithNilLocations(t *testing.T) {
 	mockRepo := new(JobRepositoryMock)
-	service := jobService{api: mockRepo}
+	service := newTestJobService(t, mockRepo)
 
 	var desc, depart, jobItem = "test", "test", "test"
-	var location []string
-	location = append(location, "test")
+	var location []models.Reference
+	location = append(location, models.Reference{Name: "test"})
 
 	body := models.CreateJobRequest{
 		Description: &desc,
-		Department:  &depart,
-		JobItem:     &jobItem,
+		Department:  &models.Reference{Name: depart},
+		JobItem:     &models.Reference{Name: jobItem},
 		Locations:   location,
 	}
 
-	dep := &models.Departments{}
+	dep := &models.Departments{Items: []models.Department{{Id: 3, Name: depart}}}
 	mockRepo.On("GetDepartments", mock.Anything, mock.Anything, mock.Anything).Return(dep, nil).Once()
 
 	var loc *models.Locations
 	mockRepo.On("GetLocations", mock.Anything).Return(loc, nil).Once()
 
-	item := &models.JobItems{}
+	item := &models.JobItems{Items: []models.JobItem{{Id: 12, DisplayName: jobItem}}}
 	mockRepo.On("GetJobItems", mock.Anything, mock.Anything, mock.Anything).Return(item, nil).Once()
 
-	_, err, httpStatusCode := service.CreateJob(&body)
+	_, err, httpStatusCode := service.CreateJob(context.Background(), &body)
 	assert.NotNil(t, err)
 	assert.Equal(t, http.StatusBadRequest, httpStatusCode)
 
@@ -168,31 +201,477 @@ func TestCreateJobWithNilLocations(t *testing.T) {
 
 func TestCreateJobWithNilJobItem(t *testing.T) {
 	mockRepo := new(JobRepositoryMock)
-	service := jobService{api: mockRepo}
+	service := newTestJobService(t, mockRepo)
 
 	var desc, depart, jobItem = "test", "test", "test"
-	var location []string
-	location = append(location, "test")
+	var location []models.Reference
+	location = append(location, models.Reference{Name: "test"})
 
 	body := models.CreateJobRequest{
 		Description: &desc,
-		Department:  &depart,
-		JobItem:     &jobItem,
+		Department:  &models.Reference{Name: depart},
+		JobItem:     &models.Reference{Name: jobItem},
 		Locations:   location,
 	}
 
-	dep := &models.Departments{}
+	dep := &models.Departments{Items: []models.Department{{Id: 3, Name: depart}}}
 	mockRepo.On("GetDepartments", mock.Anything, mock.Anything, mock.Anything).Return(dep, nil).Once()
 
-	loc := &models.Locations{}
+	loc := &models.Locations{Items: []models.Location{testLocation(1, "test", "Room", 0)}}
 	mockRepo.On("GetLocations", mock.Anything).Return(loc, nil).Once()
 
 	var item *models.JobItems
 	mockRepo.On("GetJobItems", mock.Anything, mock.Anything, mock.Anything).Return(item, nil).Once()
 
-	_, err, httpStatusCode := service.CreateJob(&body)
+	_, err, httpStatusCode := service.CreateJob(context.Background(), &body)
 	assert.NotNil(t, err)
 	assert.Equal(t, http.StatusBadRequest, httpStatusCode)
 
 	mockRepo.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+func testLocation(id int, name, locationType string, parentId int) models.Location {
+	location := models.Location{
+		Id:           id,
+		DisplayName:  &name,
+		LocationType: &models.LocationType{DisplayName: locationType},
+	}
+	if parentId > 0 {
+		location.ParentLocation = &models.LocationSimplify{Id: parentId}
+	}
+	return location
+}
+
+func TestCreateJobHousekeepingFloorExpandsToRooms(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	var depart, jobItem = "Housekeeping", "Sheets"
+	body := models.CreateJobRequest{
+		Department: &models.Reference{Name: depart},
+		JobItem:    &models.Reference{Name: jobItem},
+		Locations:  []models.Reference{{Name: "Floor 1"}},
+	}
+
+	floor := testLocation(1, "Floor 1", "Floor", 0)
+	wing := testLocation(2, "East Wing", "Wing", 1)
+	room101 := testLocation(101, "Room 101", "Room", 1)
+	room102 := testLocation(102, "Room 102", "Room", 2)
+	room201 := testLocation(201, "Room 201", "Room", 9)
+
+	mockRepo.On("GetDepartments", mock.Anything, mock.Anything, mock.Anything).
+		Return(&models.Departments{Items: []models.Department{{Id: 3, Name: "Housekeeping"}}}, nil).Once()
+	mockRepo.On("GetJobItems", mock.Anything, mock.Anything, mock.Anything).Return(&models.JobItems{Items: []models.JobItem{{Id: 12, DisplayName: jobItem}}}, nil).Once()
+	mockRepo.On("GetLocations", map[string]string{"displayName": "Floor 1", "first": "100"}).
+		Return(&models.Locations{Items: []models.Location{floor}}, nil).Once()
+	mockRepo.On("GetLocations", map[string]string{"first": "100"}).
+		Return(&models.Locations{
+			Items:    []models.Location{floor, wing, room101},
+			PageInfo: models.PageInfo{EndCursor: 3, HasNextPage: true},
+		}, nil).Once()
+	mockRepo.On("GetLocations", map[string]string{"first": "100", "next": "3"}).
+		Return(&models.Locations{Items: []models.Location{room102, room201}}, nil).Once()
+	mockRepo.On("CreateJob", mock.MatchedBy(func(job *models.Job) bool {
+		return job.Action == "clean" &&
+			job.Item.Name == "Sheets" &&
+			assert.ObjectsAreEqual([]models.Location{{Id: 101}, {Id: 102}}, job.Location)
+	})).Return(&models.Job{}, nil).Once()
+
+	result, err, httpStatusCode := service.CreateJob(context.Background(), &body)
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, http.StatusCreated, httpStatusCode)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateJobHousekeepingRejectsOtherLocationTypes(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	var depart, jobItem = "Housekeeping", "Blanket"
+	body := models.CreateJobRequest{
+		Department: &models.Reference{Name: depart},
+		JobItem:    &models.Reference{Name: jobItem},
+		Locations:  []models.Reference{{Name: "Lobby"}},
+	}
+
+	mockRepo.On("GetDepartments", mock.Anything, mock.Anything, mock.Anything).Return(&models.Departments{Items: []models.Department{{Id: 3, Name: depart}}}, nil).Once()
+	mockRepo.On("GetJobItems", mock.Anything, mock.Anything, mock.Anything).Return(&models.JobItems{Items: []models.JobItem{{Id: 12, DisplayName: jobItem}}}, nil).Once()
+	mockRepo.On("GetLocations", map[string]string{"displayName": "Lobby", "first": "100"}).
+		Return(&models.Locations{Items: []models.Location{testLocation(5, "Lobby", "Public Area", 0)}}, nil).Once()
+
+	_, err, httpStatusCode := service.CreateJob(context.Background(), &body)
+	assert.EqualError(t, err, "Housekeeping jobs need Room or Floor locations: location Lobby is of type Public Area")
+	assert.Equal(t, http.StatusBadRequest, httpStatusCode)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateJobEngineeringSingleFloorExpandsToChildren(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	var depart, jobItem = "Engineering", "Light Bulb"
+	body := models.CreateJobRequest{
+		Department: &models.Reference{Name: depart},
+		JobItem:    &models.Reference{Name: jobItem},
+		Locations:  []models.Reference{{Name: "Floor 1"}},
+	}
+
+	floor := testLocation(1, "Floor 1", "Floor", 0)
+	corridor := testLocation(2, "Corridor 1", "Corridor", 1)
+	room101 := testLocation(101, "Room 101", "Room", 1)
+	room201 := testLocation(201, "Room 201", "Room", 9)
+
+	mockRepo.On("GetDepartments", mock.Anything, mock.Anything, mock.Anything).
+		Return(&models.Departments{Items: []models.Department{{Id: 5, Name: "Engineering"}}}, nil).Once()
+	mockRepo.On("GetJobItems", mock.Anything, mock.Anything, mock.Anything).Return(&models.JobItems{Items: []models.JobItem{{Id: 12, DisplayName: jobItem}}}, nil).Once()
+	mockRepo.On("GetLocations", map[string]string{"displayName": "Floor 1", "first": "100"}).
+		Return(&models.Locations{Items: []models.Location{floor}}, nil).Once()
+	mockRepo.On("GetLocations", map[string]string{"first": "100"}).
+		Return(&models.Locations{Items: []models.Location{floor, corridor, room101, room201}}, nil).Once()
+	mockRepo.On("CreateJob", mock.MatchedBy(func(job *models.Job) bool {
+		return job.Action == "repair" &&
+			job.Item.Name == "Light Bulb" &&
+			assert.ObjectsAreEqual([]models.Location{{Id: 2}, {Id: 101}}, job.Location)
+	})).Return(&models.Job{}, nil).Once()
+
+	_, err, httpStatusCode := service.CreateJob(context.Background(), &body)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, httpStatusCode)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateJobEngineeringUsesGivenLocations(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	var depart, jobItem = "Engineering", "Light Bulb"
+	body := models.CreateJobRequest{
+		Department: &models.Reference{Name: depart},
+		JobItem:    &models.Reference{Name: jobItem},
+		Locations:  []models.Reference{{Name: "Floor 1"}, {Name: "Room 201"}},
+	}
+
+	mockRepo.On("GetDepartments", mock.Anything, mock.Anything, mock.Anything).
+		Return(&models.Departments{Items: []models.Department{{Id: 5, Name: "Engineering"}}}, nil).Once()
+	mockRepo.On("GetJobItems", mock.Anything, mock.Anything, mock.Anything).Return(&models.JobItems{Items: []models.JobItem{{Id: 12, DisplayName: jobItem}}}, nil).Once()
+	mockRepo.On("GetLocations", map[string]string{"displayName": "Floor 1", "first": "100"}).
+		Return(&models.Locations{Items: []models.Location{testLocation(1, "Floor 1", "Floor", 0)}}, nil).Once()
+	mockRepo.On("GetLocations", map[string]string{"displayName": "Room 201", "first": "100"}).
+		Return(&models.Locations{Items: []models.Location{testLocation(201, "Room 201", "Room", 9)}}, nil).Once()
+	mockRepo.On("CreateJob", mock.MatchedBy(func(job *models.Job) bool {
+		return job.Action == "repair" &&
+			assert.ObjectsAreEqual([]models.Location{{Id: 1}, {Id: 201}}, job.Location)
+	})).Return(&models.Job{}, nil).Once()
+
+	_, err, httpStatusCode := service.CreateJob(context.Background(), &body)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, httpStatusCode)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateJobRoomServiceSingleFloorDeliversToRooms(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	var desc, depart, jobItem = "Leave at the door", "Room Service", "Towels"
+	body := models.CreateJobRequest{
+		Description: &desc,
+		Department:  &models.Reference{Name: depart},
+		JobItem:     &models.Reference{Name: jobItem},
+		Locations:   []models.Reference{{Name: "Floor 1"}},
+	}
+
+	floor := testLocation(1, "Floor 1", "Floor", 0)
+	corridor := testLocation(2, "Corridor 1", "Corridor", 1)
+	room101 := testLocation(101, "Room 101", "Room", 2)
+
+	mockRepo.On("GetDepartments", mock.Anything, mock.Anything, mock.Anything).
+		Return(&models.Departments{Items: []models.Department{{Id: 7, Name: "Room Service"}}}, nil).Once()
+	mockRepo.On("GetJobItems", mock.Anything, mock.Anything, mock.Anything).Return(&models.JobItems{Items: []models.JobItem{{Id: 12, DisplayName: jobItem}}}, nil).Once()
+	mockRepo.On("GetLocations", map[string]string{"displayName": "Floor 1", "first": "100"}).
+		Return(&models.Locations{Items: []models.Location{floor}}, nil).Once()
+	mockRepo.On("GetLocations", map[string]string{"first": "100"}).
+		Return(&models.Locations{Items: []models.Location{floor, corridor, room101}}, nil).Once()
+	mockRepo.On("CreateJob", mock.MatchedBy(func(job *models.Job) bool {
+		return job.Action == "deliver" &&
+			job.Item.Name == "Towels" &&
+			job.Department.Id == 7 &&
+			assert.ObjectsAreEqual([]models.Location{{Id: 101}}, job.Location) &&
+			assert.ObjectsAreEqual([]models.Notes{{Note: "Leave at the door"}}, job.Notes)
+	})).Return(&models.Job{}, nil).Once()
+
+	_, err, httpStatusCode := service.CreateJob(context.Background(), &body)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, httpStatusCode)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateJobWithoutMatchingRule(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	var depart, jobItem = "Housekeeping", "Towels"
+	body := models.CreateJobRequest{
+		Department: &models.Reference{Name: depart},
+		JobItem:    &models.Reference{Name: jobItem},
+		Locations:  []models.Reference{{Name: "Room 101"}},
+	}
+
+	mockRepo.On("GetDepartments", mock.Anything, mock.Anything, mock.Anything).Return(&models.Departments{Items: []models.Department{{Id: 3, Name: depart}}}, nil).Once()
+	mockRepo.On("GetJobItems", mock.Anything, mock.Anything, mock.Anything).Return(&models.JobItems{Items: []models.JobItem{{Id: 12, DisplayName: jobItem}}}, nil).Once()
+	mockRepo.On("GetLocations", mock.Anything).
+		Return(&models.Locations{Items: []models.Location{testLocation(101, "Room 101", "Room", 1)}}, nil).Once()
+
+	_, err, httpStatusCode := service.CreateJob(context.Background(), &body)
+	assert.EqualError(t, err, "no job rule matches department Housekeeping and job item Towels")
+	assert.Equal(t, http.StatusBadRequest, httpStatusCode)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDryRunDoesNotCreateJob(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	var depart, jobItem = "Housekeeping", "Mattress"
+	body := models.CreateJobRequest{
+		Department: &models.Reference{Name: depart},
+		JobItem:    &models.Reference{Name: jobItem},
+		Locations:  []models.Reference{{Name: "Floor 1"}, {Name: "Room 201"}},
+	}
+
+	floor := testLocation(1, "Floor 1", "Floor", 0)
+	room101 := testLocation(101, "Room 101", "Room", 1)
+	room201 := testLocation(201, "Room 201", "Room", 2)
+
+	mockRepo.On("GetDepartments", mock.Anything, mock.Anything, mock.Anything).
+		Return(&models.Departments{Items: []models.Department{{Id: 3, Name: "Housekeeping"}}}, nil).Once()
+	mockRepo.On("GetJobItems", mock.Anything, mock.Anything, mock.Anything).
+		Return(&models.JobItems{Items: []models.JobItem{{Id: 12, DisplayName: "Mattress"}}}, nil).Once()
+	mockRepo.On("GetLocations", map[string]string{"displayName": "Floor 1", "first": "100"}).
+		Return(&models.Locations{Items: []models.Location{floor}}, nil).Once()
+	mockRepo.On("GetLocations", map[string]string{"displayName": "Room 201", "first": "100"}).
+		Return(&models.Locations{Items: []models.Location{room201}}, nil).Once()
+	mockRepo.On("GetLocations", map[string]string{"first": "100"}).
+		Return(&models.Locations{Items: []models.Location{floor, room101, room201}}, nil).Once()
+
+	dryRun, err, httpStatusCode := service.DryRun(context.Background(), &body)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, httpStatusCode)
+	assert.Equal(t, "housekeeping-clean-beds", dryRun.Rule)
+	assert.Equal(t, 3, dryRun.Department.Id)
+	assert.Equal(t, 12, dryRun.JobItem.Id)
+	assert.Equal(t, []int{1, 201}, dryRun.Locations)
+	assert.Equal(t, []models.LocationExpansion{{Location: 1, Locations: []int{101}}}, dryRun.Expansions)
+	if assert.Len(t, dryRun.Jobs, 1) {
+		assert.Equal(t, "clean", dryRun.Jobs[0].Action)
+		assert.Equal(t, []models.Location{{Id: 101}, {Id: 201}}, dryRun.Jobs[0].Location)
+	}
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "CreateJob", mock.Anything)
+}
+
+func TestCreateJobWithIds(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	body := models.CreateJobRequest{
+		Department: &models.Reference{Id: 7},
+		JobItem:    &models.Reference{Id: 12},
+		Locations:  []models.Reference{{Id: 101}},
+	}
+
+	room := testLocation(101, "Room 101", "Room", 1)
+	mockRepo.On("GetDepartment", 7).Return(&models.Department{Id: 7, Name: "Room Service"}, nil).Once()
+	mockRepo.On("GetJobItem", 12).Return(&models.JobItem{Id: 12, DisplayName: "Towels"}, nil).Once()
+	mockRepo.On("GetLocation", 101).Return(&room, nil).Once()
+	mockRepo.On("CreateJob", mock.MatchedBy(func(job *models.Job) bool {
+		return job.Action == "deliver" &&
+			job.Item.Name == "Towels" &&
+			job.Department.Id == 7 &&
+			assert.ObjectsAreEqual([]models.Location{{Id: 101}}, job.Location)
+	})).Return(&models.Job{}, nil).Once()
+
+	_, err, httpStatusCode := service.CreateJob(context.Background(), &body)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, httpStatusCode)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetDepartments", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "GetLocations", mock.Anything)
+}
+
+func TestCreateJobRejectsPartialDepartmentMatch(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	var depart, jobItem = "Housekeeping", "Sheets"
+	body := models.CreateJobRequest{
+		Department: &models.Reference{Name: depart},
+		JobItem:    &models.Reference{Name: jobItem},
+		Locations:  []models.Reference{{Name: "Room 101"}},
+	}
+
+	mockRepo.On("GetDepartments", "Housekeeping", 100, 0).
+		Return(&models.Departments{Items: []models.Department{{Id: 4, Name: "Housekeeping Night Shift"}}}, nil).Once()
+	mockRepo.On("GetJobItems", 100, 0, "Sheets").
+		Return(&models.JobItems{Items: []models.JobItem{{Id: 12, DisplayName: "sheets"}}}, nil).Once()
+	mockRepo.On("GetLocations", mock.Anything).
+		Return(&models.Locations{Items: []models.Location{testLocation(101, "Room 101", "Room", 1)}}, nil).Once()
+
+	_, err, httpStatusCode := service.CreateJob(context.Background(), &body)
+	assert.EqualError(t, err, "invalid department: Housekeeping")
+	assert.Equal(t, http.StatusBadRequest, httpStatusCode)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateJobRejectsAmbiguousLocation(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	var depart, jobItem = "Housekeeping", "Sheets"
+	body := models.CreateJobRequest{
+		Department: &models.Reference{Name: depart},
+		JobItem:    &models.Reference{Name: jobItem},
+		Locations:  []models.Reference{{Name: "Room 101"}},
+	}
+
+	mockRepo.On("GetDepartments", mock.Anything, mock.Anything, mock.Anything).Return(&models.Departments{Items: []models.Department{{Id: 3, Name: depart}}}, nil).Once()
+	mockRepo.On("GetJobItems", mock.Anything, mock.Anything, mock.Anything).Return(&models.JobItems{Items: []models.JobItem{{Id: 12, DisplayName: jobItem}}}, nil).Once()
+	mockRepo.On("GetLocations", map[string]string{"displayName": "Room 101", "first": "100"}).
+		Return(&models.Locations{
+			Items:    []models.Location{testLocation(101, "Room 101", "Room", 1), testLocation(1101, "Room 1101", "Room", 11)},
+			PageInfo: models.PageInfo{EndCursor: 2, HasNextPage: true},
+		}, nil).Once()
+	mockRepo.On("GetLocations", map[string]string{"displayName": "Room 101", "first": "100", "next": "2"}).
+		Return(&models.Locations{Items: []models.Location{testLocation(901, "ROOM 101", "Room", 9)}}, nil).Once()
+
+	_, err, httpStatusCode := service.CreateJob(context.Background(), &body)
+	assert.EqualError(t, err, "ambiguous location Room 101, candidates: Room 101 (id 101), ROOM 101 (id 901)")
+	assert.Equal(t, http.StatusBadRequest, httpStatusCode)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateJobKeepsOptiiClientErrorStatus(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	body := models.CreateJobRequest{
+		Department: &models.Reference{Id: 7},
+		JobItem:    &models.Reference{Id: 12},
+		Locations:  []models.Reference{{Id: 101}},
+	}
+
+	room := testLocation(101, "Room 101", "Room", 1)
+	mockRepo.On("GetDepartment", 7).Return(&models.Department{Id: 7, Name: "Room Service"}, nil).Once()
+	mockRepo.On("GetJobItem", 12).Return(&models.JobItem{Id: 12, DisplayName: "Towels"}, nil).Once()
+	mockRepo.On("GetLocation", 101).Return(&room, nil).Once()
+	mockRepo.On("CreateJob", mock.Anything).
+		Return((*models.Job)(nil), &api.Error{StatusCode: http.StatusUnprocessableEntity, Message: "location 101 is inactive"}).Once()
+
+	_, err, httpStatusCode := service.CreateJob(context.Background(), &body)
+	assert.ErrorContains(t, err, "location 101 is inactive")
+	assert.Equal(t, http.StatusUnprocessableEntity, httpStatusCode)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateJobReportsOptiiFailures(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		httpStatus int
+		message    string
+	}{
+		{"unknown id", &api.Error{StatusCode: http.StatusNotFound}, http.StatusBadRequest, "invalid department: 7"},
+		{"expired credentials", &api.Error{StatusCode: http.StatusUnauthorized}, http.StatusBadGateway, "looking up department 7: optii"},
+		{"server error", &api.Error{StatusCode: http.StatusServiceUnavailable}, http.StatusBadGateway, "looking up department 7: optii"},
+		{"timeout", context.DeadlineExceeded, http.StatusGatewayTimeout, "looking up department 7: context deadline exceeded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(JobRepositoryMock)
+			service := newTestJobService(t, mockRepo)
+
+			body := models.CreateJobRequest{
+				Department: &models.Reference{Id: 7},
+				JobItem:    &models.Reference{Id: 12},
+				Locations:  []models.Reference{{Id: 101}},
+			}
+
+			room := testLocation(101, "Room 101", "Room", 1)
+			mockRepo.On("GetDepartment", 7).Return((*models.Department)(nil), tt.err).Once()
+			mockRepo.On("GetJobItem", 12).Return(&models.JobItem{Id: 12, DisplayName: "Towels"}, nil).Once()
+			mockRepo.On("GetLocation", 101).Return(&room, nil).Once()
+
+			_, err, httpStatusCode := service.CreateJob(context.Background(), &body)
+			assert.ErrorContains(t, err, tt.message)
+			assert.Equal(t, tt.httpStatus, httpStatusCode)
+		})
+	}
+}
+
+func TestCreateJobRuleWithinBuilding(t *testing.T) {
+	custom := fstest.MapFS{
+		"spa.yaml": {Data: []byte("name: spa-building-a\ndepartment: Spa\naction: restock\nlocations:\n  within: [Building A]\n")},
+	}
+	engine, err := rules.NewEngine(rules.Defaults(), custom)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	building := testLocation(1, "Building A", "Building", 0)
+	floor := testLocation(2, "Floor 1", "Floor", 1)
+	room := testLocation(101, "Room 101", "Room", 2)
+	annex := testLocation(900, "Annex Room", "Room", 0)
+
+	tests := []struct {
+		name       string
+		location   models.Location
+		httpStatus int
+		err        string
+	}{
+		{"inside", room, http.StatusOK, ""},
+		{"outside", annex, http.StatusBadRequest, "rule spa-building-a: location Annex Room is not within Building A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(JobRepositoryMock)
+			service := NewJobService(mockRepo, engine, NewJobBuilder(), locations.NewIndex(mockRepo))
+
+			mockRepo.On("GetDepartment", 5).Return(&models.Department{Id: 5, Name: "Spa"}, nil).Once()
+			mockRepo.On("GetJobItem", 12).Return(&models.JobItem{Id: 12, DisplayName: "Robe"}, nil).Once()
+			mockRepo.On("GetLocation", tt.location.Id).Return(&tt.location, nil).Once()
+			mockRepo.On("GetLocations", map[string]string{"first": "100"}).
+				Return(&models.Locations{Items: []models.Location{building, floor, room, annex}}, nil).Once()
+
+			body := models.CreateJobRequest{
+				Department: &models.Reference{Id: 5},
+				JobItem:    &models.Reference{Id: 12},
+				Locations:  []models.Reference{{Id: tt.location.Id}},
+			}
+
+			result, err, httpStatusCode := service.DryRun(context.Background(), &body)
+			assert.Equal(t, tt.httpStatus, httpStatusCode)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "spa-building-a", result.Rule)
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"optii/models"
+)
+
+// UpdateJob reassigns, reprioritises, reschedules or moves a job to another status.
+// Status changes are checked against the current status of the job before anything is written. Optii has no way to
+// change both at once, so the other fields are written first and are kept when Optii then refuses the status change;
+// the error says so.
+func (s *jobService) UpdateJob(ctx context.Context, id int, update *models.UpdateJobRequest) (*models.Job, error, int) {
+	patch := models.JobPatch{DueBy: update.DueBy}
+	if update.Priority != nil {
+		priority := strings.ToLower(*update.Priority)
+		if !contains(jobPriorities, priority) {
+			return nil, &JobValidationError{Field: "priority", Reason: fmt.Sprintf("%s is not one of %v", priority, jobPriorities)}, http.StatusBadRequest
+		}
+		patch.Priority = priority
+	}
+	if update.Assignee != nil {
+		if *update.Assignee <= 0 {
+			return nil, &JobValidationError{Field: "assignee", Reason: "must be a positive employee id"}, http.StatusBadRequest
+		}
+		patch.Assignee = &models.AssigneePatch{EmployeeId: *update.Assignee}
+	}
+	var status models.JobStatus
+	if update.Status != nil {
+		var err error
+		if status, err = models.ParseJobStatus(string(*update.Status)); err != nil {
+			return nil, &JobValidationError{Field: "status", Reason: err.Error()}, http.StatusBadRequest
+		}
+		if status == models.JobStatusCancelled {
+			return nil, &JobValidationError{Field: "status", Reason: "cancel jobs with POST /jobs/{id}/cancel"}, http.StatusBadRequest
+		}
+	}
+
+	hasPatch := patch.Priority != "" || patch.Assignee != nil || patch.DueBy != nil
+	if !hasPatch && status == "" {
+		return nil, fmt.Errorf("nothing to update"), http.StatusBadRequest
+	}
+
+	if status != "" {
+		if err, httpStatus := s.checkTransition(ctx, id, status); err != nil {
+			return nil, err, httpStatus
+		}
+	}
+
+	var job *models.Job
+	var err error
+	if hasPatch {
+		job, err = s.api.UpdateJob(ctx, id, &patch)
+		if err != nil {
+			return nil, err, upstreamStatus(err, http.StatusBadGateway)
+		}
+	}
+	if status != "" {
+		job, err = s.api.TransitionJob(ctx, id, status)
+		if err != nil && hasPatch {
+			return nil, fmt.Errorf("job %d was updated but its status was not changed: %w", id, err), upstreamStatus(err, http.StatusBadGateway)
+		}
+		if err != nil {
+			return nil, err, upstreamStatus(err, http.StatusBadGateway)
+		}
+	}
+
+	return job, nil, http.StatusOK
+}
+
+// CancelJob cancels a job that is not completed or cancelled yet.
+func (s *jobService) CancelJob(ctx context.Context, id int, reason string) (*models.Job, error, int) {
+	if err, httpStatus := s.checkTransition(ctx, id, models.JobStatusCancelled); err != nil {
+		return nil, err, httpStatus
+	}
+
+	return proxied(s.api.CancelJob(ctx, id, strings.TrimSpace(reason)))
+}
+
+// AddNote adds a note to a job.
+func (s *jobService) AddNote(ctx context.Context, id int, note string) (*models.Notes, error, int) {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil, &JobValidationError{Field: "note", Reason: "must not be empty"}, http.StatusBadRequest
+	}
+
+	created, err, httpStatus := proxied(s.api.AddJobNote(ctx, id, note))
+	if err != nil {
+		return nil, err, httpStatus
+	}
+	return created, nil, http.StatusCreated
+}
+
+// checkTransition fetches the job and reports a conflict when it may not move to the given status.
+func (s *jobService) checkTransition(ctx context.Context, id int, status models.JobStatus) (error, int) {
+	job, err, httpStatus := proxied(s.api.GetJob(ctx, id))
+	if err != nil {
+		return err, httpStatus
+	}
+	// Without a reported status Optii is left to decide.
+	if job.Status == "" {
+		return nil, http.StatusOK
+	}
+	if job.Status == status {
+		return fmt.Errorf("job %d is already %s", id, status), http.StatusConflict
+	}
+	if !job.Status.CanTransitionTo(status) {
+		return fmt.Errorf("job %d cannot move from %s to %s", id, job.Status, status), http.StatusConflict
+	}
+	return nil, http.StatusOK
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"optii/api"
+	"optii/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateJobPatchesFields(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	priority, assignee := "High", 42
+	dueBy := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mockRepo.On("UpdateJob", 7, &models.JobPatch{Priority: "high", Assignee: &models.AssigneePatch{EmployeeId: 42}, DueBy: &dueBy}).
+		Return(&models.Job{Id: 7, Priority: "high"}, nil).Once()
+
+	job, err, httpStatus := service.UpdateJob(context.Background(), 7, &models.UpdateJobRequest{Priority: &priority, Assignee: &assignee, DueBy: &dueBy})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, httpStatus)
+	assert.Equal(t, "high", job.Priority)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetJob", mock.Anything)
+}
+
+func TestUpdateJobTransitionsStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		current    models.JobStatus
+		next       models.JobStatus
+		httpStatus int
+		err        string
+	}{
+		{"start", models.JobStatusNew, models.JobStatusInProgress, http.StatusOK, ""},
+		{"complete", models.JobStatusInProgress, models.JobStatusCompleted, http.StatusOK, ""},
+		{"resume", models.JobStatusOnHold, "INPROGRESS", http.StatusOK, ""},
+		{"reopen", models.JobStatusCompleted, models.JobStatusPending, http.StatusConflict, "job 7 cannot move from completed to pending"},
+		{"complete on hold", models.JobStatusOnHold, models.JobStatusCompleted, http.StatusConflict, "job 7 cannot move from onHold to completed"},
+		{"same status", models.JobStatusPending, models.JobStatusPending, http.StatusConflict, "job 7 is already pending"},
+		{"cancel", models.JobStatusNew, models.JobStatusCancelled, http.StatusBadRequest, "invalid job status: cancel jobs with POST /jobs/{id}/cancel"},
+		{"unknown", models.JobStatusNew, "done", http.StatusBadRequest, "invalid job status: unknown job status done"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(JobRepositoryMock)
+			service := newTestJobService(t, mockRepo)
+
+			mockRepo.On("GetJob", 7).Return(&models.Job{Id: 7, Status: tt.current}, nil).Maybe()
+			mockRepo.On("TransitionJob", 7, mock.Anything).Return(&models.Job{Id: 7, Status: models.JobStatusInProgress}, nil).Maybe()
+
+			next := tt.next
+			_, err, httpStatus := service.UpdateJob(context.Background(), 7, &models.UpdateJobRequest{Status: &next})
+			assert.Equal(t, tt.httpStatus, httpStatus)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				mockRepo.AssertNotCalled(t, "TransitionJob", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			mockRepo.AssertCalled(t, "TransitionJob", 7, mock.Anything)
+		})
+	}
+}
+
+func TestUpdateJobReportsAPatchKeptWithoutTheStatus(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	priority, status := "high", models.JobStatusCompleted
+	mockRepo.On("GetJob", 7).Return(&models.Job{Id: 7, Status: models.JobStatusInProgress}, nil).Once()
+	mockRepo.On("UpdateJob", 7, &models.JobPatch{Priority: "high"}).Return(&models.Job{Id: 7, Priority: "high"}, nil).Once()
+	mockRepo.On("TransitionJob", 7, models.JobStatusCompleted).
+		Return((*models.Job)(nil), &api.Error{StatusCode: http.StatusUnprocessableEntity, Message: "job has open tasks"}).Once()
+
+	_, err, httpStatus := service.UpdateJob(context.Background(), 7, &models.UpdateJobRequest{Priority: &priority, Status: &status})
+	assert.ErrorContains(t, err, "job 7 was updated but its status was not changed: ")
+	assert.Equal(t, http.StatusUnprocessableEntity, httpStatus)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateJobRejectsInvalidFields(t *testing.T) {
+	priority, assignee := "urgent", -1
+
+	tests := map[string]models.UpdateJobRequest{
+		"empty":    {},
+		"priority": {Priority: &priority},
+		"assignee": {Assignee: &assignee},
+	}
+
+	for name, update := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(JobRepositoryMock)
+			service := newTestJobService(t, mockRepo)
+
+			_, err, httpStatus := service.UpdateJob(context.Background(), 7, &update)
+			assert.Error(t, err)
+			assert.Equal(t, http.StatusBadRequest, httpStatus)
+			mockRepo.AssertNotCalled(t, "UpdateJob", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCancelJob(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	mockRepo.On("GetJob", 7).Return(&models.Job{Id: 7, Status: models.JobStatusPending}, nil).Once()
+	mockRepo.On("CancelJob", 7, "Guest checked out").Return(&models.Job{Id: 7, Status: models.JobStatusCancelled}, nil).Once()
+	mockRepo.On("GetJob", 8).Return(&models.Job{Id: 8, Status: models.JobStatusCompleted}, nil).Once()
+
+	job, err, httpStatus := service.CancelJob(context.Background(), 7, " Guest checked out ")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, httpStatus)
+	assert.Equal(t, models.JobStatusCancelled, job.Status)
+
+	_, err, httpStatus = service.CancelJob(context.Background(), 8, "")
+	assert.EqualError(t, err, "job 8 cannot move from completed to cancelled")
+	assert.Equal(t, http.StatusConflict, httpStatus)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCancelUnknownJob(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	mockRepo.On("GetJob", 9).Return((*models.Job)(nil), &api.Error{StatusCode: http.StatusNotFound}).Once()
+
+	_, _, httpStatus := service.CancelJob(context.Background(), 9, "")
+	assert.Equal(t, http.StatusNotFound, httpStatus)
+	mockRepo.AssertNotCalled(t, "CancelJob", mock.Anything, mock.Anything)
+}
+
+func TestAddNote(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := newTestJobService(t, mockRepo)
+
+	mockRepo.On("AddJobNote", 7, "Extra pillows").Return(&models.Notes{Id: 3, Note: "Extra pillows"}, nil).Once()
+
+	note, err, httpStatus := service.AddNote(context.Background(), 7, "Extra pillows\n")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, httpStatus)
+	assert.Equal(t, 3, note.Id)
+
+	_, err, httpStatus = service.AddNote(context.Background(), 7, "  ")
+	assert.EqualError(t, err, "invalid job note: must not be empty")
+	assert.Equal(t, http.StatusBadRequest, httpStatus)
+
+	mockRepo.AssertExpectations(t)
+}
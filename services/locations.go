@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"optii/locations"
+	"optii/models"
+)
+
+// LocationQuery filters the locations of the property.
+type LocationQuery struct {
+	// Type keeps the locations of that type together with the locations leading to them. Empty keeps every location.
+	Type string
+	// Depth is the number of levels returned below the starting point. Zero returns every level.
+	Depth int
+}
+
+type LocationService interface {
+	// Tree returns the location hierarchy of the property, starting at the locations that are not below any other.
+	Tree(ctx context.Context, query LocationQuery) ([]models.LocationNode, error, int)
+	// Children returns the hierarchy below the given location.
+	Children(ctx context.Context, id int, query LocationQuery) ([]models.LocationNode, error, int)
+}
+
+type locationService struct {
+	hierarchy locations.Index
+}
+
+func NewLocationService(hierarchy locations.Index) LocationService {
+	return &locationService{hierarchy: hierarchy}
+}
+
+func (s *locationService) Tree(ctx context.Context, query LocationQuery) ([]models.LocationNode, error, int) {
+	tree, err := s.hierarchy.Tree(ctx)
+	if err != nil {
+		return nil, err, upstreamStatus(err, http.StatusInternalServerError)
+	}
+
+	return locationNodes(tree, tree.Roots(), query, 1), nil, http.StatusOK
+}
+
+func (s *locationService) Children(ctx context.Context, id int, query LocationQuery) ([]models.LocationNode, error, int) {
+	tree, err := s.hierarchy.Tree(ctx)
+	if err != nil {
+		return nil, err, upstreamStatus(err, http.StatusInternalServerError)
+	}
+	if _, ok := tree.Get(id); !ok {
+		return nil, fmt.Errorf("location %d not found", id), http.StatusNotFound
+	}
+
+	return locationNodes(tree, tree.Children(id), query, 1), nil, http.StatusOK
+}
+
+// locationNodes nests the given locations, found at the given level, down to the depth of the query.
+// With a type, locations are only kept when they are of that type or lead to one within the depth.
+func locationNodes(tree *locations.Tree, level []models.Location, query LocationQuery, depth int) []models.LocationNode {
+	nodes := make([]models.LocationNode, 0, len(level))
+	for _, location := range level {
+		node := models.LocationNode{Id: location.Id, Name: locations.Name(location)}
+		if location.LocationType != nil {
+			node.Type = location.LocationType.DisplayName
+		}
+		if query.Depth == 0 || depth < query.Depth {
+			node.Children = locationNodes(tree, tree.Children(location.Id), query, depth+1)
+		}
+
+		if query.Type != "" && !locations.IsType(location, query.Type) && len(node.Children) == 0 {
+			continue
+		}
+		if len(node.Children) == 0 {
+			node.Children = nil
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"optii/api"
+	"optii/locations"
+	"optii/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// staticIndex serves a fixed location tree.
+type staticIndex struct {
+	tree *locations.Tree
+	err  error
+}
+
+func (i *staticIndex) Tree(ctx context.Context) (*locations.Tree, error) { return i.tree, i.err }
+func (i *staticIndex) Refresh(ctx context.Context) error                 { return i.err }
+func (i *staticIndex) LoadedAt() time.Time                               { return time.Time{} }
+
+func newTestLocationService() LocationService {
+	return NewLocationService(&staticIndex{tree: locations.Build([]models.Location{
+		testLocation(1, "Building A", "Building", 0),
+		testLocation(3, "Floor 3", "Floor", 1),
+		testLocation(31, "East Wing", "Wing", 3),
+		testLocation(301, "Room 301", "Room", 3),
+		testLocation(311, "Room 311", "Room", 31),
+		testLocation(4, "Floor 4", "Floor", 1),
+		testLocation(5, "Lobby", "Public Area", 0),
+	})})
+}
+
+func TestLocationTree(t *testing.T) {
+	service := newTestLocationService()
+
+	tree, err, httpStatus := service.Tree(context.Background(), LocationQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, httpStatus)
+	assert.Equal(t, []models.LocationNode{
+		{Id: 1, Name: "Building A", Type: "Building", Children: []models.LocationNode{
+			{Id: 3, Name: "Floor 3", Type: "Floor", Children: []models.LocationNode{
+				{Id: 31, Name: "East Wing", Type: "Wing", Children: []models.LocationNode{
+					{Id: 311, Name: "Room 311", Type: "Room"},
+				}},
+				{Id: 301, Name: "Room 301", Type: "Room"},
+			}},
+			{Id: 4, Name: "Floor 4", Type: "Floor"},
+		}},
+		{Id: 5, Name: "Lobby", Type: "Public Area"},
+	}, tree)
+}
+
+func TestLocationTreeFilters(t *testing.T) {
+	service := newTestLocationService()
+
+	tree, _, _ := service.Tree(context.Background(), LocationQuery{Depth: 2})
+	assert.Equal(t, []models.LocationNode{
+		{Id: 1, Name: "Building A", Type: "Building", Children: []models.LocationNode{
+			{Id: 3, Name: "Floor 3", Type: "Floor"},
+			{Id: 4, Name: "Floor 4", Type: "Floor"},
+		}},
+		{Id: 5, Name: "Lobby", Type: "Public Area"},
+	}, tree)
+
+	tree, _, _ = service.Tree(context.Background(), LocationQuery{Type: "floor"})
+	assert.Equal(t, []models.LocationNode{
+		{Id: 1, Name: "Building A", Type: "Building", Children: []models.LocationNode{
+			{Id: 3, Name: "Floor 3", Type: "Floor"},
+			{Id: 4, Name: "Floor 4", Type: "Floor"},
+		}},
+	}, tree)
+}
+
+func TestLocationChildren(t *testing.T) {
+	service := newTestLocationService()
+
+	children, err, httpStatus := service.Children(context.Background(), 3, LocationQuery{Type: "Room", Depth: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, httpStatus)
+	assert.Equal(t, []models.LocationNode{{Id: 301, Name: "Room 301", Type: "Room"}}, children)
+
+	children, _, _ = service.Children(context.Background(), 3, LocationQuery{Type: "Room"})
+	assert.Equal(t, []models.LocationNode{
+		{Id: 31, Name: "East Wing", Type: "Wing", Children: []models.LocationNode{{Id: 311, Name: "Room 311", Type: "Room"}}},
+		{Id: 301, Name: "Room 301", Type: "Room"},
+	}, children)
+
+	children, _, _ = service.Children(context.Background(), 4, LocationQuery{Depth: 1})
+	assert.Equal(t, []models.LocationNode{}, children)
+
+	_, err, httpStatus = service.Children(context.Background(), 1234, LocationQuery{})
+	assert.EqualError(t, err, "location 1234 not found")
+	assert.Equal(t, http.StatusNotFound, httpStatus)
+}
+
+func TestLocationTreeReportsOptiiFailure(t *testing.T) {
+	service := NewLocationService(&staticIndex{err: &api.Error{StatusCode: http.StatusServiceUnavailable}})
+
+	_, err, httpStatus := service.Tree(context.Background(), LocationQuery{})
+	var apiErr *api.Error
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadGateway, httpStatus)
+}
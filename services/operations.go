@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"optii/models"
+	"optii/operations"
+)
+
+// OperationService handles job requests in the background, for requests that may take longer than a client waits.
+type OperationService interface {
+	// CreateJob queues the creation of the job and returns its pending operation.
+	CreateJob(ctx context.Context, job *models.CreateJobRequest) (*models.Operation, error, int)
+	GetOperation(ctx context.Context, id string) (*models.Operation, error, int)
+}
+
+type operationService struct {
+	jobs   JobService
+	runner operations.Runner
+}
+
+func NewOperationService(jobs JobService, runner operations.Runner) OperationService {
+	return &operationService{jobs: jobs, runner: runner}
+}
+
+func (s *operationService) CreateJob(ctx context.Context, job *models.CreateJobRequest) (*models.Operation, error, int) {
+	operation, err := s.runner.Submit(ctx, func(ctx context.Context) (operations.Result, error, int) {
+		created, err, httpStatus := s.jobs.CreateJob(ctx, job)
+		var queued *JobQueuedError
+		if errors.As(err, &queued) {
+			// The outbox delivers the job once Optii is back.
+			return operations.Result{OutboxEntryId: queued.Entry.Id}, nil, httpStatus
+		}
+		var duplicate *DuplicateJobError
+		if errors.As(err, &duplicate) {
+			return operations.Result{DuplicateJobId: duplicate.JobId}, err, httpStatus
+		}
+		var dead *JobDeadLetteredError
+		if errors.As(err, &dead) {
+			return operations.Result{OutboxEntryId: dead.Entry.Id}, err, httpStatus
+		}
+		if err != nil {
+			return operations.Result{}, err, httpStatus
+		}
+		return operations.Result{JobIds: []int{created.Id}}, nil, httpStatus
+	})
+	if errors.Is(err, operations.ErrQueueFull) {
+		return nil, err, http.StatusServiceUnavailable
+	}
+	if err != nil {
+		return nil, err, http.StatusInternalServerError
+	}
+	return &operation, nil, http.StatusAccepted
+}
+
+func (s *operationService) GetOperation(ctx context.Context, id string) (*models.Operation, error, int) {
+	operation, ok := s.runner.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("operation %s not found", id), http.StatusNotFound
+	}
+	return &operation, nil, http.StatusOK
+}
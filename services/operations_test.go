@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"optii/models"
+	"optii/operations"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stubJobService creates jobs with the given function.
+type stubJobService struct {
+	JobService
+	create func(job *models.CreateJobRequest) (*models.Job, error, int)
+}
+
+func (s *stubJobService) CreateJob(ctx context.Context, job *models.CreateJobRequest) (*models.Job, error, int) {
+	return s.create(job)
+}
+
+func TestCreateJobInTheBackground(t *testing.T) {
+	jobs := &stubJobService{create: func(job *models.CreateJobRequest) (*models.Job, error, int) {
+		switch job.Department.Id {
+		case 7:
+			return &models.Job{Id: 41}, nil, http.StatusCreated
+		case 9:
+			return nil, &JobQueuedError{Entry: models.OutboxEntry{Id: "abc"}}, http.StatusAccepted
+		case 11:
+			return nil, &DuplicateJobError{JobId: 41}, http.StatusConflict
+		case 10:
+			return nil, &JobDeadLetteredError{Entry: models.OutboxEntry{Id: "def", Status: models.OutboxDead}}, http.StatusBadGateway
+		}
+		return nil, errors.New("invalid department: 8"), http.StatusBadRequest
+	}}
+	service := NewOperationService(jobs, operations.NewRunner(operations.Config{}))
+
+	created, err, httpStatus := service.CreateJob(context.Background(), &models.CreateJobRequest{Department: &models.Reference{Id: 7}})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, httpStatus)
+	rejected, _, _ := service.CreateJob(context.Background(), &models.CreateJobRequest{Department: &models.Reference{Id: 8}})
+	queued, _, _ := service.CreateJob(context.Background(), &models.CreateJobRequest{Department: &models.Reference{Id: 9}})
+	dead, _, _ := service.CreateJob(context.Background(), &models.CreateJobRequest{Department: &models.Reference{Id: 10}})
+	duplicate, _, _ := service.CreateJob(context.Background(), &models.CreateJobRequest{Department: &models.Reference{Id: 11}})
+
+	assert.Eventually(t, func() bool {
+		operation, _, _ := service.GetOperation(context.Background(), created.Id)
+		return operation.Status == models.OperationSucceeded && assert.ObjectsAreEqual([]int{41}, operation.JobIds)
+	}, time.Second, time.Millisecond*5)
+	assert.Eventually(t, func() bool {
+		operation, _, _ := service.GetOperation(context.Background(), rejected.Id)
+		return operation.Status == models.OperationFailed && operation.Error == "invalid department: 8"
+	}, time.Second, time.Millisecond*5)
+	assert.Eventually(t, func() bool {
+		operation, _, _ := service.GetOperation(context.Background(), queued.Id)
+		return operation.Status == models.OperationQueued && operation.OutboxEntryId == "abc" &&
+			operation.JobIds == nil && operation.HttpStatus == http.StatusAccepted
+	}, time.Second, time.Millisecond*5)
+	assert.Eventually(t, func() bool {
+		operation, _, _ := service.GetOperation(context.Background(), dead.Id)
+		return operation.Status == models.OperationFailed && operation.OutboxEntryId == "def" &&
+			operation.HttpStatus == http.StatusBadGateway
+	}, time.Second, time.Millisecond*5)
+	assert.Eventually(t, func() bool {
+		operation, _, _ := service.GetOperation(context.Background(), duplicate.Id)
+		return operation.Status == models.OperationFailed && operation.JobId == 41 && operation.HttpStatus == http.StatusConflict
+	}, time.Second, time.Millisecond*5)
+
+	_, err, httpStatus = service.GetOperation(context.Background(), "unknown")
+	assert.EqualError(t, err, "operation unknown not found")
+	assert.Equal(t, http.StatusNotFound, httpStatus)
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"optii/models"
+	"optii/outbox"
+)
+
+// OutboxService inspects the jobs saved in the outbox and replays the dead-lettered ones.
+type OutboxService interface {
+	GetEntry(ctx context.Context, id string) (*models.OutboxEntry, error, int)
+	DeadLetters(ctx context.Context) ([]models.OutboxEntry, error, int)
+	Replay(ctx context.Context, id string) (*models.OutboxEntry, error, int)
+}
+
+type outboxService struct {
+	outbox outbox.Outbox
+}
+
+func NewOutboxService(outbox outbox.Outbox) OutboxService {
+	return &outboxService{outbox: outbox}
+}
+
+func (s *outboxService) GetEntry(ctx context.Context, id string) (*models.OutboxEntry, error, int) {
+	entry, ok := s.outbox.Get(id)
+	if !ok {
+		return nil, outbox.ErrNotFound, http.StatusNotFound
+	}
+	return &entry, nil, http.StatusOK
+}
+
+func (s *outboxService) DeadLetters(ctx context.Context) ([]models.OutboxEntry, error, int) {
+	entries := s.outbox.DeadLetters()
+	if entries == nil {
+		entries = []models.OutboxEntry{}
+	}
+	return entries, nil, http.StatusOK
+}
+
+func (s *outboxService) Replay(ctx context.Context, id string) (*models.OutboxEntry, error, int) {
+	entry, err := s.outbox.Replay(id)
+	switch {
+	case errors.Is(err, outbox.ErrNotFound):
+		return nil, err, http.StatusNotFound
+	case errors.Is(err, outbox.ErrNotDeadLetter):
+		return nil, err, http.StatusConflict
+	case err != nil:
+		return nil, err, http.StatusInternalServerError
+	}
+	return &entry, nil, http.StatusOK
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"optii/models"
+	"optii/outbox"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// deadLetters is an outbox holding the given dead letters.
+type deadLetters struct {
+	outbox.Outbox
+	entries []models.OutboxEntry
+}
+
+func (o *deadLetters) Get(id string) (models.OutboxEntry, bool) {
+	for _, entry := range o.entries {
+		if entry.Id == id {
+			return entry, true
+		}
+	}
+	return models.OutboxEntry{}, false
+}
+
+func (o *deadLetters) DeadLetters() []models.OutboxEntry {
+	return o.entries
+}
+
+func (o *deadLetters) Replay(id string) (models.OutboxEntry, error) {
+	entry, ok := o.Get(id)
+	if !ok {
+		return entry, outbox.ErrNotFound
+	}
+	if entry.Status != models.OutboxDead {
+		return entry, outbox.ErrNotDeadLetter
+	}
+	entry.Status = models.OutboxPending
+	return entry, nil
+}
+
+func TestOutboxService(t *testing.T) {
+	service := NewOutboxService(&deadLetters{entries: []models.OutboxEntry{
+		{Id: "a", Status: models.OutboxDead},
+		{Id: "b", Status: models.OutboxPending},
+	}})
+
+	entry, err, httpStatus := service.GetEntry(context.Background(), "b")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, httpStatus)
+	assert.Equal(t, models.OutboxPending, entry.Status)
+
+	_, _, httpStatus = service.GetEntry(context.Background(), "c")
+	assert.Equal(t, http.StatusNotFound, httpStatus)
+
+	entry, err, httpStatus = service.Replay(context.Background(), "a")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, httpStatus)
+	assert.Equal(t, models.OutboxPending, entry.Status)
+
+	_, _, httpStatus = service.Replay(context.Background(), "b")
+	assert.Equal(t, http.StatusConflict, httpStatus)
+
+	_, _, httpStatus = service.Replay(context.Background(), "c")
+	assert.Equal(t, http.StatusNotFound, httpStatus)
+
+	entries, _, _ := NewOutboxService(&deadLetters{}).DeadLetters(context.Background())
+	assert.NotNil(t, entries, "an empty list is still a list")
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"optii/api"
+	"optii/models"
+)
+
+// ReferenceQuery selects a page of reference data. First and Next are passed to Optii as they are, zero leaves them out.
+type ReferenceQuery struct {
+	DisplayName string
+	First       int
+	Next        int
+}
+
+// ReferenceService reads departments, job items and location types from Optii on behalf of other clients.
+type ReferenceService interface {
+	GetDepartments(ctx context.Context, query ReferenceQuery) (*models.Departments, error, int)
+	GetDepartment(ctx context.Context, id int) (*models.Department, error, int)
+	GetJobItems(ctx context.Context, query ReferenceQuery) (*models.JobItems, error, int)
+	GetJobItem(ctx context.Context, id int) (*models.JobItem, error, int)
+	GetLocationTypes(ctx context.Context, query ReferenceQuery) (*models.LocationTypes, error, int)
+	GetLocationType(ctx context.Context, id int) (*models.LocationType, error, int)
+}
+
+type referenceService struct {
+	api api.OptiiApi
+}
+
+func NewReferenceService(api api.OptiiApi) ReferenceService {
+	return &referenceService{api: api}
+}
+
+func (s *referenceService) GetDepartments(ctx context.Context, query ReferenceQuery) (*models.Departments, error, int) {
+	return proxied(s.api.GetDepartments(ctx, query.DisplayName, query.First, query.Next))
+}
+
+func (s *referenceService) GetDepartment(ctx context.Context, id int) (*models.Department, error, int) {
+	return proxied(s.api.GetDepartment(ctx, id))
+}
+
+func (s *referenceService) GetJobItems(ctx context.Context, query ReferenceQuery) (*models.JobItems, error, int) {
+	return proxied(s.api.GetJobItems(ctx, query.First, query.Next, query.DisplayName))
+}
+
+func (s *referenceService) GetJobItem(ctx context.Context, id int) (*models.JobItem, error, int) {
+	return proxied(s.api.GetJobItem(ctx, id))
+}
+
+// GetLocationTypes returns a page of location types. Optii cannot filter them by display name,
+// so the display name filter is applied to the returned page.
+func (s *referenceService) GetLocationTypes(ctx context.Context, query ReferenceQuery) (*models.LocationTypes, error, int) {
+	page, err, httpStatus := proxied(s.api.GetLocationTypes(ctx, query.First, query.Next))
+	if err != nil || query.DisplayName == "" {
+		return page, err, httpStatus
+	}
+
+	filtered := *page
+	filtered.Items = []models.LocationType{}
+	for _, locationType := range page.Items {
+		if strings.Contains(strings.ToLower(locationType.DisplayName), strings.ToLower(query.DisplayName)) {
+			filtered.Items = append(filtered.Items, locationType)
+		}
+	}
+	return &filtered, nil, http.StatusOK
+}
+
+func (s *referenceService) GetLocationType(ctx context.Context, id int) (*models.LocationType, error, int) {
+	return proxied(s.api.GetLocationType(ctx, id))
+}
+
+// proxied reports the result of an Optii call made on behalf of a client.
+// Statuses Optii rejected the request with are kept, so an unknown id is still a 404.
+func proxied[T any](result *T, err error) (*T, error, int) {
+	if err != nil {
+		return nil, err, upstreamStatus(err, http.StatusBadGateway)
+	}
+	return result, nil, http.StatusOK
+}
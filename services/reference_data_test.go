@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"optii/api"
+	"optii/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReferenceServicePassesQueryThrough(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := NewReferenceService(mockRepo)
+
+	departments := &models.Departments{Items: []models.Department{{Id: 3, Name: "Housekeeping"}}}
+	mockRepo.On("GetDepartments", "House", 10, 20).Return(departments, nil).Once()
+	jobItems := &models.JobItems{Items: []models.JobItem{{Id: 12, DisplayName: "Sheets"}}}
+	mockRepo.On("GetJobItems", 10, 0, "Sheets").Return(jobItems, nil).Once()
+
+	result, err, httpStatus := service.GetDepartments(context.Background(), ReferenceQuery{DisplayName: "House", First: 10, Next: 20})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, httpStatus)
+	assert.Equal(t, departments, result)
+
+	items, _, _ := service.GetJobItems(context.Background(), ReferenceQuery{DisplayName: "Sheets", First: 10})
+	assert.Equal(t, jobItems, items)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReferenceServiceFiltersLocationTypes(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := NewReferenceService(mockRepo)
+
+	mockRepo.On("GetLocationTypes", 0, 0).Return(&models.LocationTypes{
+		Items:    []models.LocationType{{Id: 1, DisplayName: "Room"}, {Id: 2, DisplayName: "Floor"}, {Id: 3, DisplayName: "Meeting Room"}},
+		PageInfo: models.PageInfo{TotalCount: 3},
+	}, nil).Twice()
+
+	result, _, _ := service.GetLocationTypes(context.Background(), ReferenceQuery{DisplayName: "room"})
+	assert.Equal(t, []models.LocationType{{Id: 1, DisplayName: "Room"}, {Id: 3, DisplayName: "Meeting Room"}}, result.Items)
+	assert.Equal(t, 3, result.PageInfo.TotalCount)
+
+	result, _, _ = service.GetLocationTypes(context.Background(), ReferenceQuery{DisplayName: "Lobby"})
+	assert.Equal(t, []models.LocationType{}, result.Items)
+}
+
+func TestReferenceServiceKeepsNotFound(t *testing.T) {
+	mockRepo := new(JobRepositoryMock)
+	service := NewReferenceService(mockRepo)
+
+	mockRepo.On("GetJobItem", 99).Return((*models.JobItem)(nil), &api.Error{StatusCode: http.StatusNotFound}).Once()
+	mockRepo.On("GetLocationType", 5).Return((*models.LocationType)(nil), &api.Error{StatusCode: http.StatusInternalServerError}).Once()
+
+	_, err, httpStatus := service.GetJobItem(context.Background(), 99)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusNotFound, httpStatus)
+
+	_, err, httpStatus = service.GetLocationType(context.Background(), 5)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadGateway, httpStatus)
+}
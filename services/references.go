@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"optii/api"
+	"optii/models"
+)
+
+// findDepartment resolves a department reference, looking it up only once per batch of job requests.
+func (s *jobService) findDepartment(ctx context.Context, department models.Reference) (*models.Department, error, int) {
+	return memoized(s.lookups, "department", department, func() (*models.Department, error, int) {
+		return s.lookupDepartment(ctx, department)
+	})
+}
+
+// lookupDepartment resolves a department reference. Ids are fetched directly, names must match exactly one department.
+func (s *jobService) lookupDepartment(ctx context.Context, department models.Reference) (*models.Department, error, int) {
+	if department.IsId() {
+		dep, err := s.api.GetDepartment(ctx, department.Id)
+		if err != nil || dep == nil {
+			err, httpStatus := referenceError("department", department, err)
+			return nil, err, httpStatus
+		}
+		return dep, nil, http.StatusOK
+	}
+
+	return findExact("department", department.Name,
+		api.IterateDepartments(ctx, s.api, department.Name),
+		func(dep models.Department) (int, string) {
+			return dep.Id, departmentName(dep)
+		})
+}
+
+// findJobItem resolves a job item reference, looking it up only once per batch of job requests.
+func (s *jobService) findJobItem(ctx context.Context, jobItem models.Reference) (*models.JobItem, error, int) {
+	return memoized(s.lookups, "job item", jobItem, func() (*models.JobItem, error, int) {
+		return s.lookupJobItem(ctx, jobItem)
+	})
+}
+
+// lookupJobItem resolves a job item reference. Ids are fetched directly, names must match exactly one job item.
+func (s *jobService) lookupJobItem(ctx context.Context, jobItem models.Reference) (*models.JobItem, error, int) {
+	if jobItem.IsId() {
+		item, err := s.api.GetJobItem(ctx, jobItem.Id)
+		if err != nil || item == nil {
+			err, httpStatus := referenceError("job item", jobItem, err)
+			return nil, err, httpStatus
+		}
+		return item, nil, http.StatusOK
+	}
+
+	return findExact("job item", jobItem.Name,
+		api.IterateJobItems(ctx, s.api, jobItem.Name),
+		func(item models.JobItem) (int, string) {
+			return item.Id, item.DisplayName
+		})
+}
+
+// findLocation resolves a location reference, looking it up only once per batch of job requests.
+func (s *jobService) findLocation(ctx context.Context, location models.Reference) (*models.Location, error, int) {
+	return memoized(s.lookups, "location", location, func() (*models.Location, error, int) {
+		return s.lookupLocation(ctx, location)
+	})
+}
+
+// lookupLocation resolves a location reference. Ids are fetched directly, names must match exactly one location.
+func (s *jobService) lookupLocation(ctx context.Context, location models.Reference) (*models.Location, error, int) {
+	if location.IsId() {
+		loc, err := s.api.GetLocation(ctx, location.Id)
+		if err != nil || loc == nil {
+			err, httpStatus := referenceError("location", location, err)
+			return nil, err, httpStatus
+		}
+		return loc, nil, http.StatusOK
+	}
+
+	return findExact("location", location.Name,
+		api.IterateLocations(ctx, s.api, map[string]string{"displayName": location.Name}),
+		func(loc models.Location) (int, string) {
+			if loc.DisplayName == nil {
+				return loc.Id, ""
+			}
+			return loc.Id, *loc.DisplayName
+		})
+}
+
+// resolveLocations looks up every given location, returning an error for the first one that cannot be resolved.
+func (s *jobService) resolveLocations(ctx context.Context, references []models.Reference) ([]models.Location, error, int) {
+	locations := make([]models.Location, 0, len(references))
+	for _, reference := range references {
+		location, err, httpStatus := s.findLocation(ctx, reference)
+		if err != nil {
+			return nil, err, httpStatus
+		}
+		locations = append(locations, *location)
+	}
+
+	return locations, nil, http.StatusOK
+}
+
+// findExact goes through every result of a display name search and returns the only item whose display name
+// equals name, ignoring case. Optii also returns partial matches, so the search results alone prove nothing.
+// More than one exact match is reported as ambiguous together with the candidates.
+func findExact[T any](entity, name string, results *api.Iterator[T], describe func(T) (int, string)) (*T, error, int) {
+	var matches []T
+	for results.Next() {
+		if _, displayName := describe(results.Item()); strings.EqualFold(displayName, name) {
+			matches = append(matches, results.Item())
+		}
+	}
+	if err := results.Err(); err != nil {
+		err, httpStatus := referenceError(entity, models.Reference{Name: name}, err)
+		return nil, err, httpStatus
+	}
+
+	switch len(matches) {
+	case 0:
+		err, httpStatus := referenceError(entity, models.Reference{Name: name}, nil)
+		return nil, err, httpStatus
+	case 1:
+		return &matches[0], nil, http.StatusOK
+	}
+
+	candidates := make([]string, len(matches))
+	for i, match := range matches {
+		id, displayName := describe(match)
+		candidates[i] = fmt.Sprintf("%s (id %d)", displayName, id)
+	}
+	return nil, fmt.Errorf("ambiguous %s %s, candidates: %s", entity, name, strings.Join(candidates, ", ")), http.StatusBadRequest
+}
+
+// referenceError describes why a reference could not be resolved, together with the HTTP status to report.
+// Anything Optii does not know is an invalid reference, other failures are reported as upstream failures.
+func referenceError(entity string, reference models.Reference, err error) (error, int) {
+	var apiErr *api.Error
+	if err == nil || (errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound) {
+		return fmt.Errorf("invalid %s: %s", entity, reference), http.StatusBadRequest
+	}
+	return fmt.Errorf("looking up %s %s: %w", entity, reference, err), upstreamStatus(err, http.StatusBadGateway)
+}
+
+func departmentName(department models.Department) string {
+	if department.DisplayName != "" {
+		return department.DisplayName
+	}
+	return department.Name
+}